@@ -10,26 +10,91 @@ import (
 type API interface {
 	Pull(*PullConfig) (UpdateChannel, ErrorChannel)
 	Push(*Update) (*Update, error)
+	Edit(*Update) (*Update, error)
+	AnswerCallback(u *Update, text string, alert bool) error
+	Approve(u *Update, approve bool, reason string) error
+	IsChatAdmin(u *Update) (bool, error)
 
 	Platform() string
 	ParseUserID(u *Update, s string) (int64, error)
 	ats(u *User) []string
 }
 
+// Types of updates.
+const (
+	UpdateMessage        = "message_text"
+	UpdateMessageEdited  = "message_edited"
+	UpdateChannelPost    = "channel_post"
+	UpdateMessageDeleted = "message_deleted"
+	UpdateCallbackQuery  = "callback_query"
+	UpdateMemberJoined   = "member_joined"
+	UpdateMemberLeft     = "member_left"
+	UpdateMemberKicked   = "member_kicked"
+	UpdateAdminChanged   = "admin_changed"
+	UpdateGroupRenamed   = "group_renamed"
+	UpdateFriendRequest  = "friend_request"
+	UpdateGroupRequest   = "group_request"
+)
+
 // Update is a struct for an update of APIs.
+//
+// Operator is the user causing an event if it is not the User, such as the one recalling a message of another user or kicking a member.
+// Notice is the details of a notice update.
 type Update struct {
 	ID   int64
 	Type string
 
-	Chat    *Chat
-	User    *User
-	Message *Message
+	Chat     *Chat
+	User     *User
+	Operator *User
+	Message  *Message
+	Callback *Callback
+	Notice   *Notice
+	Request  *Request
 
 	Time time.Time
 
 	Bot *Bot
 }
 
+// link sets the Update of the parts of the update.
+func (u *Update) link() {
+	if u.Message != nil {
+		u.Message.Update = u
+	}
+	if u.Callback != nil {
+		u.Callback.Update = u
+	}
+	if u.Chat != nil {
+		u.Chat.Update = u
+	}
+	if u.User != nil {
+		u.User.Update = u
+	}
+	if u.Operator != nil {
+		u.Operator.Update = u
+	}
+}
+
+// Notice is a struct for the details of a notice update.
+//
+// Admin is true if the User becomes an admin in an admin_changed update.
+// Title is the new title of the chat in a group_renamed update.
+type Notice struct {
+	Admin bool
+	Title string
+}
+
+// Request is a struct for a request of adding a friend or joining a group.
+//
+// ID is the platform-specific flag used for approving or rejecting the request.
+// SubType is "add" if the User asks to join the group, or "invite" if the User invites the bot into the group.
+type Request struct {
+	ID      string
+	SubType string
+	Comment string
+}
+
 // UpdateChannel is a channel for saving updates.
 type UpdateChannel chan *Update
 
@@ -48,19 +113,58 @@ type PullConfig struct {
 }
 
 // Message is a struct for a message of an update.
+//
+// Segments is the parsed message. Content of a received message is the plain text of Segments, see PlainText. A pushed message is rendered from Segments if it is not empty, otherwise Content is sent as it is, so that messages with platform markup made by At are still supported.
+// ReplyTo is the message replied by the message, a pushed message quotes it if it is not nil.
+// Keyboard is the rows of buttons attached to a pushed message, it is only supported by Telegram.
+// Params is the positional arguments parsed by the Args of the command.
+// Captures is the captures of the trigger of the command matching the message.
 type Message struct {
 	ID   int64
 	Type string
 
-	Content string
+	Content  string
+	Segments []Segment
 
-	Args    []string
-	Command string
-	Flags   map[string]*pflag.FlagSet
+	ReplyTo  *ReplyTo
+	Keyboard [][]Button
+
+	Args     []string
+	Command  string
+	Flags    map[string]*pflag.FlagSet
+	Params   map[string]interface{}
+	Captures map[string]string
+
+	Update *Update
+}
+
+// Button is a struct for a button of a keyboard attached to a message.
+//
+// Data is sent back by a callback query when the button is pressed, or URL is opened if it is not empty.
+type Button struct {
+	Text string
+	Data string
+	URL  string
+}
+
+// Callback is a struct for a callback query sent by pressing a button, Message of the update is the message with the button.
+type Callback struct {
+	ID   string
+	Data string
 
 	Update *Update
 }
 
+// ReplyTo is a struct for a message replied by another message.
+//
+// User and Excerpt may be empty if the platform does not provide them.
+type ReplyTo struct {
+	ID int64
+
+	User    *User
+	Excerpt string
+}
+
 // Chat is a struct for a chat.
 type Chat struct {
 	ID   int64
@@ -0,0 +1,224 @@
+package botmaid
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIConsole is a struct stores some basic information of a console. It reads messages line by line from Reader and prints replies to Writer, so that commands can be tried without any platform account.
+//
+// Every line read from Reader is regarded as a message sent by the user UserID in the chat ChatID.
+type APIConsole struct {
+	Reader io.Reader
+	Writer io.Writer
+
+	UserID   int64
+	UserName string
+	ChatID   int64
+	ChatType string
+
+	mu     sync.Mutex
+	lastID int64
+}
+
+func (a *APIConsole) nextID() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.lastID++
+	return a.lastID
+}
+
+func (a *APIConsole) writer() io.Writer {
+	if a.Writer == nil {
+		return os.Stdout
+	}
+	return a.Writer
+}
+
+func (a *APIConsole) lineToUpdate(s string) *Update {
+	id := a.nextID()
+
+	chatType := a.ChatType
+	if chatType == "" {
+		chatType = "private"
+	}
+
+	update := &Update{
+		ID: id,
+
+		Type: UpdateMessage,
+
+		Time: time.Now(),
+
+		Chat: &Chat{
+			ID:   a.ChatID,
+			Type: chatType,
+		},
+
+		User: &User{
+			ID:       a.UserID,
+			UserName: a.UserName,
+			NickName: a.UserName,
+		},
+
+		Message: &Message{
+			ID:      id,
+			Content: s,
+			Segments: []Segment{
+				{
+					Type: SegmentText,
+					Text: s,
+				},
+			},
+		},
+	}
+
+	update.link()
+
+	return update
+}
+
+// Pull pulls updates and errors into the channels with a given config.
+func (a *APIConsole) Pull(pc *PullConfig) (UpdateChannel, ErrorChannel) {
+	updates := make(chan *Update)
+	errors := make(chan error)
+
+	r := a.Reader
+	if r == nil {
+		r = os.Stdin
+	}
+
+	go func() {
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			if strings.TrimSpace(s.Text()) == "" {
+				continue
+			}
+			updates <- a.lineToUpdate(s.Text())
+		}
+
+		if err := s.Err(); err != nil {
+			errors <- fmt.Errorf("Read console: %v", err)
+		}
+	}()
+
+	return updates, errors
+}
+
+// Push pushes an update and returns it back if existing.
+func (a *APIConsole) Push(update *Update) (*Update, error) {
+	if update.Type == "Delete" {
+		_, err := fmt.Fprintf(a.writer(), "[Delete #%v]\n", update.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Delete message: %v", err)
+		}
+
+		return nil, nil
+	}
+
+	s := strings.TrimSpace(update.Message.PlainText())
+	if len(update.Message.Segments) == 0 && update.Message.Type != "" && update.Message.Type != "Text" {
+		s = fmt.Sprintf("[%v] %v", update.Message.Type, s)
+	}
+
+	if update.Message.ReplyTo != nil {
+		s = fmt.Sprintf("(Reply to #%v) %v", update.Message.ReplyTo.ID, s)
+	}
+	for _, r := range update.Message.Keyboard {
+		bs := []string{}
+		for _, b := range r {
+			if b.URL != "" {
+				bs = append(bs, fmt.Sprintf("[%v](%v)", b.Text, b.URL))
+			} else {
+				bs = append(bs, fmt.Sprintf("[%v](%v)", b.Text, b.Data))
+			}
+		}
+		s += "\n" + strings.Join(bs, " ")
+	}
+
+	update.ID = a.nextID()
+
+	_, err := fmt.Fprintf(a.writer(), "#%v %v\n", update.ID, s)
+	if err != nil {
+		return nil, fmt.Errorf("Send message: %v", err)
+	}
+
+	return update, nil
+}
+
+// Edit prints the new content of a message.
+func (a *APIConsole) Edit(update *Update) (*Update, error) {
+	_, err := fmt.Fprintf(a.writer(), "[Edit #%v] %v\n", update.ID, strings.TrimSpace(update.Message.PlainText()))
+	if err != nil {
+		return nil, fmt.Errorf("Edit message: %v", err)
+	}
+	return update, nil
+}
+
+// Approve prints the result of a request.
+func (a *APIConsole) Approve(u *Update, approve bool, reason string) error {
+	_, err := fmt.Fprintf(a.writer(), "[Approve %v] %v %v\n", u.Request.ID, approve, reason)
+	if err != nil {
+		return fmt.Errorf("Approve request: %v", err)
+	}
+	return nil
+}
+
+// IsChatAdmin always returns false since there is no admin in the console.
+func (a *APIConsole) IsChatAdmin(u *Update) (bool, error) {
+	return false, nil
+}
+
+// AnswerCallback prints the answer of a callback query.
+func (a *APIConsole) AnswerCallback(u *Update, text string, alert bool) error {
+	if text == "" {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(a.writer(), "[Answer] %v\n", text)
+	if err != nil {
+		return fmt.Errorf("Answer callback query: %v", err)
+	}
+	return nil
+}
+
+// Platform returns a string showing the platform of the bot.
+func (a *APIConsole) Platform() string {
+	return "Console"
+}
+
+// ParseUserID parses the ID of the User in the At string, which is "@" followed by the user name or the ID.
+func (a *APIConsole) ParseUserID(u *Update, s string) (int64, error) {
+	if !strings.HasPrefix(s, "@") {
+		return 0, errors.New("Invalid At string")
+	}
+	s = s[1:]
+
+	if s == a.UserName {
+		return a.UserID, nil
+	}
+	if u != nil && u.Bot != nil && u.Bot.Self != nil && s == u.Bot.Self.UserName {
+		return u.Bot.Self.ID, nil
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid At string: %v", err)
+	}
+	return id, nil
+}
+
+func (a *APIConsole) ats(u *User) []string {
+	if u.UserName == "" {
+		return []string{fmt.Sprintf("@%v", u.ID)}
+	}
+	return []string{fmt.Sprintf("@%v", u.UserName), fmt.Sprintf("@%v", u.ID)}
+}
@@ -8,21 +8,53 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
 // APICqhttp is a struct stores some basic information of the CQHTTP. Please search in CQHTTP document for details.
+//
+// If ReverseListen is set, botmaid listens on it and the CQHTTP connects to ReversePath by reverse WebSocket, otherwise botmaid connects to WebsocketEndpoint and reconnects with exponential backoff after disconnection. Both universal and separate API and Event reverse clients are supported by the X-Client-Role header, API calls are sent through the latest API or universal connection.
+//
+// If APIOverWebsocket is set, API calls are sent through the WebSocket and time out after APITimeout, they fall back to HTTP if the WebSocket fails and APIEndpoint is not empty.
 type APICqhttp struct {
-	AccessToken string
-	Secret      string
-	APIEndpoint string
+	AccessToken       string
+	Secret            string
+	APIEndpoint       string
 	WebsocketEndpoint string
+
+	ReverseListen string
+	ReversePath   string
+
+	APIOverWebsocket bool
+	APITimeout       time.Duration
+
+	once    sync.Once
+	updates chan *Update
+	errors  chan error
+	events  chan map[string]interface{}
+
+	connMu  sync.Mutex
+	writeMu sync.Mutex
+	conn    *websocket.Conn
+	ready   chan struct{}
+
+	pendingMu sync.Mutex
+	pending   map[string]chan map[string]interface{}
+	echo      int64
 }
 
+const (
+	maxRetryWaitingTimeCqhttp = time.Minute
+	defaultAPITimeoutCqhttp   = time.Second * 10
+)
+
 var (
 	retDescCqhttp = map[int]string{
 		0:     "Succeeded",
@@ -36,8 +68,90 @@ var (
 	}
 )
 
-// API returns the body of an HTTP response to the CQHTTP.
+// API returns the data of a response to the CQHTTP.
+//
+// A call over the WebSocket falls back to HTTP only if it has not been sent, so that a call timing out after sending is not done twice.
 func (a *APICqhttp) API(end string, m map[string]interface{}) (interface{}, error) {
+	if a.APIOverWebsocket {
+		ret, sent, err := a.apiWebsocket(end, m)
+		if err == nil {
+			return a.result(end, ret)
+		}
+		if sent || a.APIEndpoint == "" {
+			return nil, err
+		}
+	}
+
+	ret, err := a.apiHTTP(end, m)
+	if err != nil {
+		return nil, err
+	}
+	return a.result(end, ret)
+}
+
+// apiWebsocket calls the API over the WebSocket, sent is true if the call has been written into the WebSocket.
+func (a *APICqhttp) apiWebsocket(end string, m map[string]interface{}) (ret map[string]interface{}, sent bool, err error) {
+	a.connect(&PullConfig{
+		RetryWaitingTime: time.Second * 3,
+	})
+
+	timeout := a.APITimeout
+	if timeout == 0 {
+		timeout = defaultAPITimeoutCqhttp
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	a.connMu.Lock()
+	ready := a.ready
+	a.connMu.Unlock()
+
+	select {
+	case <-ready:
+	case <-timer.C:
+		return nil, false, fmt.Errorf("API %v: WebSocket is not connected", end)
+	}
+
+	a.connMu.Lock()
+	conn := a.conn
+	a.connMu.Unlock()
+	if conn == nil {
+		return nil, false, fmt.Errorf("API %v: WebSocket is not connected", end)
+	}
+
+	echo := strconv.FormatInt(atomic.AddInt64(&a.echo, 1), 10)
+	ch := make(chan map[string]interface{}, 1)
+
+	a.pendingMu.Lock()
+	a.pending[echo] = ch
+	a.pendingMu.Unlock()
+
+	defer func() {
+		a.pendingMu.Lock()
+		delete(a.pending, echo)
+		a.pendingMu.Unlock()
+	}()
+
+	a.writeMu.Lock()
+	err = conn.WriteJSON(map[string]interface{}{
+		"action": end,
+		"params": m,
+		"echo":   echo,
+	})
+	a.writeMu.Unlock()
+	if err != nil {
+		return nil, false, fmt.Errorf("API %v: %v", end, err)
+	}
+
+	select {
+	case ret = <-ch:
+		return ret, true, nil
+	case <-timer.C:
+		return nil, true, fmt.Errorf("API %v: Timeout", end)
+	}
+}
+
+func (a *APICqhttp) apiHTTP(end string, m map[string]interface{}) (map[string]interface{}, error) {
 	url := fmt.Sprintf(a.APIEndpoint, end, a.AccessToken)
 
 	j, err := json.Marshal(m)
@@ -64,6 +178,10 @@ func (a *APICqhttp) API(end string, m map[string]interface{}) (interface{}, erro
 		return nil, fmt.Errorf("API %v: %v", end, err)
 	}
 
+	return ret, nil
+}
+
+func (a *APICqhttp) result(end string, ret map[string]interface{}) (interface{}, error) {
 	if _, ok := ret["status"]; !ok {
 		return nil, fmt.Errorf("API %v: Unsuccessful request", end)
 	}
@@ -78,6 +196,324 @@ func (a *APICqhttp) API(end string, m map[string]interface{}) (interface{}, erro
 	return ret["data"], nil
 }
 
+var (
+	cqUnescaper    = strings.NewReplacer("&#91;", "[", "&#93;", "]", "&#44;", ",", "&amp;", "&")
+	cqTextEscaper  = strings.NewReplacer("&", "&amp;", "[", "&#91;", "]", "&#93;")
+	cqParamEscaper = strings.NewReplacer("&", "&amp;", "[", "&#91;", "]", "&#93;", ",", "&#44;")
+)
+
+// parseCQSegments parses a message with CQ codes into segments, unknown CQ codes are dropped.
+func parseCQSegments(s string) []Segment {
+	ss := []Segment{}
+
+	for s != "" {
+		i := strings.Index(s, "[CQ:")
+		j := -1
+		if i >= 0 {
+			j = strings.Index(s[i:], "]")
+		}
+		if i < 0 || j < 0 {
+			ss = append(ss, Segment{
+				Type: SegmentText,
+				Text: cqUnescaper.Replace(s),
+			})
+			break
+		}
+		if i > 0 {
+			ss = append(ss, Segment{
+				Type: SegmentText,
+				Text: cqUnescaper.Replace(s[:i]),
+			})
+		}
+
+		parts := strings.Split(s[i+4:i+j], ",")
+		s = s[i+j+1:]
+
+		params := map[string]string{}
+		for _, p := range parts[1:] {
+			kv := strings.SplitN(p, "=", 2)
+			if len(kv) == 2 {
+				params[kv[0]] = cqUnescaper.Replace(kv[1])
+			}
+		}
+
+		file := params["file"]
+		if params["url"] != "" {
+			file = params["url"]
+		}
+
+		switch parts[0] {
+		case "at":
+			id, _ := strconv.ParseInt(params["qq"], 10, 64)
+			seg := Segment{
+				Type:   SegmentMention,
+				UserID: id,
+				Text:   params["name"],
+			}
+			if params["qq"] == "all" {
+				seg.Text = "all"
+			}
+			ss = append(ss, seg)
+		case "image":
+			ss = append(ss, Segment{
+				Type: SegmentImage,
+				File: file,
+			})
+		case "record":
+			ss = append(ss, Segment{
+				Type: SegmentAudio,
+				File: file,
+			})
+		case "video":
+			ss = append(ss, Segment{
+				Type: SegmentVideo,
+				File: file,
+			})
+		case "reply":
+			id, _ := strconv.ParseInt(params["id"], 10, 64)
+			ss = append(ss, Segment{
+				Type:      SegmentReply,
+				MessageID: id,
+			})
+		case "face":
+			ss = append(ss, Segment{
+				Type: SegmentEmoji,
+				File: params["id"],
+			})
+		case "share":
+			ss = append(ss, Segment{
+				Type: SegmentLink,
+				URL:  params["url"],
+				Text: params["title"],
+			})
+		}
+	}
+
+	return ss
+}
+
+// cqFile returns the file parameter of a CQ code, local files are encoded in base64.
+func cqFile(file string) (string, error) {
+	if isURL(file) || strings.HasPrefix(file, "base64://") || strings.HasPrefix(file, "file://") {
+		return file, nil
+	}
+
+	if _, err := os.Stat(file); err != nil {
+		return file, nil
+	}
+
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("Read file: %v", err)
+	}
+	return "base64://" + base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func (a *APICqhttp) renderSegments(ss []Segment) (string, error) {
+	message := ""
+
+	for _, v := range ss {
+		switch v.Type {
+		case SegmentText:
+			message += cqTextEscaper.Replace(v.Text)
+		case SegmentMention:
+			if v.UserID == 0 && v.Text == "all" {
+				message += "[CQ:at,qq=all]"
+			} else {
+				message += fmt.Sprintf("[CQ:at,qq=%v]", v.UserID)
+			}
+		case SegmentImage, SegmentAudio, SegmentVideo:
+			file, err := cqFile(v.File)
+			if err != nil {
+				return "", err
+			}
+
+			t := "image"
+			if v.Type == SegmentAudio {
+				t = "record"
+			} else if v.Type == SegmentVideo {
+				t = "video"
+			}
+			message += fmt.Sprintf("[CQ:%v,file=%v]", t, cqParamEscaper.Replace(file))
+		case SegmentFile:
+			message += cqTextEscaper.Replace(v.File)
+		case SegmentReply:
+			message = fmt.Sprintf("[CQ:reply,id=%v]", v.MessageID) + message
+		case SegmentEmoji:
+			if v.File != "" {
+				message += fmt.Sprintf("[CQ:face,id=%v]", cqParamEscaper.Replace(v.File))
+			} else {
+				message += cqTextEscaper.Replace(v.Text)
+			}
+		case SegmentLink:
+			title := v.Text
+			if title == "" {
+				title = v.URL
+			}
+			message += fmt.Sprintf("[CQ:share,url=%v,title=%v]", cqParamEscaper.Replace(v.URL), cqParamEscaper.Replace(title))
+		}
+	}
+
+	return message, nil
+}
+
+// replyTo returns the replied message with its sender and excerpt if they could be got.
+func (a *APICqhttp) replyTo(id int64) *ReplyTo {
+	rt := &ReplyTo{
+		ID: id,
+	}
+
+	m, err := a.API("get_msg", map[string]interface{}{
+		"message_id": id,
+	})
+	if err != nil {
+		return rt
+	}
+
+	msg, ok := m.(map[string]interface{})
+	if !ok {
+		return rt
+	}
+
+	if sender, ok := msg["sender"].(map[string]interface{}); ok {
+		if uid, ok := sender["user_id"].(float64); ok {
+			rt.User = &User{
+				ID:       int64(uid),
+				UserName: strconv.FormatInt(int64(uid), 10),
+			}
+			rt.User.NickName, _ = sender["nickname"].(string)
+		}
+	}
+
+	if raw, ok := msg["message"].(string); ok {
+		rt.Excerpt = excerpt((&Message{Segments: parseCQSegments(raw)}).PlainText())
+	}
+
+	return rt
+}
+
+func (a *APICqhttp) groupTitle(id int64) (string, error) {
+	m, err := a.API("get_group_list", map[string]interface{}{})
+	if err != nil {
+		return "", err
+	}
+
+	gs := m.([]interface{})
+	for _, v := range gs {
+		g := v.(map[string]interface{})
+		if int64(g["group_id"].(float64)) == id {
+			return g["group_name"].(string), nil
+		}
+	}
+
+	return "", nil
+}
+
+// noticeToUpdate converts a notice into an update, it returns nil if the notice is not supported.
+func (a *APICqhttp) noticeToUpdate(e map[string]interface{}) (*Update, error) {
+	id := func(k string) int64 {
+		f, _ := e[k].(float64)
+		return int64(f)
+	}
+	user := func(k string) *User {
+		return &User{
+			ID:       id(k),
+			UserName: strconv.FormatInt(id(k), 10),
+		}
+	}
+
+	update := &Update{
+		Time: time.Unix(id("time"), 0),
+		User: user("user_id"),
+	}
+
+	switch e["notice_type"] {
+	case "group_recall", "friend_recall":
+		update.ID = id("message_id")
+		update.Type = UpdateMessageDeleted
+		update.Message = &Message{
+			ID: id("message_id"),
+		}
+		if _, ok := e["operator_id"]; ok && id("operator_id") != id("user_id") {
+			update.Operator = user("operator_id")
+		}
+	case "group_increase":
+		update.Type = UpdateMemberJoined
+		if id("operator_id") != 0 && id("operator_id") != id("user_id") {
+			update.Operator = user("operator_id")
+		}
+	case "group_decrease":
+		update.Type = UpdateMemberLeft
+		if e["sub_type"] != "leave" {
+			update.Type = UpdateMemberKicked
+			update.Operator = user("operator_id")
+		}
+	case "group_admin":
+		update.Type = UpdateAdminChanged
+		update.Notice = &Notice{
+			Admin: e["sub_type"] == "set",
+		}
+	default:
+		return nil, nil
+	}
+
+	// The title is fetched by an API call, so it is only fetched for supported notices.
+	if groupID := id("group_id"); groupID != 0 {
+		title, err := a.groupTitle(groupID)
+		if err != nil {
+			return nil, err
+		}
+		update.Chat = &Chat{
+			ID:    groupID,
+			Type:  "group",
+			Title: title,
+		}
+	} else {
+		update.Chat = &Chat{
+			ID:   id("user_id"),
+			Type: "private",
+		}
+	}
+
+	return update, nil
+}
+
+// requestToUpdate converts a request into an update, it returns nil if the request is not supported.
+func (a *APICqhttp) requestToUpdate(e map[string]interface{}) *Update {
+	userID := int64(e["user_id"].(float64))
+
+	update := &Update{
+		Time: time.Unix(int64(e["time"].(float64)), 0),
+		User: &User{
+			ID:       userID,
+			UserName: strconv.FormatInt(userID, 10),
+		},
+		Request: &Request{},
+	}
+	update.Request.ID, _ = e["flag"].(string)
+	update.Request.SubType, _ = e["sub_type"].(string)
+	update.Request.Comment, _ = e["comment"].(string)
+
+	switch e["request_type"] {
+	case "friend":
+		update.Type = UpdateFriendRequest
+		update.Chat = &Chat{
+			ID:   userID,
+			Type: "private",
+		}
+	case "group":
+		update.Type = UpdateGroupRequest
+		update.Chat = &Chat{
+			ID:   int64(e["group_id"].(float64)),
+			Type: "group",
+		}
+	default:
+		return nil
+	}
+
+	return update
+}
+
 func (a *APICqhttp) mapToUpdates(m []interface{}) ([]*Update, error) {
 	us := []*Update{}
 	for _, v := range m {
@@ -85,11 +521,11 @@ func (a *APICqhttp) mapToUpdates(m []interface{}) ([]*Update, error) {
 
 		update := &Update{}
 
-		if e["post_type"].(string) == "message" {
+		if postType, _ := e["post_type"].(string); postType == "message" {
 			update = &Update{
 				ID: int64(e["message_id"].(float64)),
 
-				Type: "message_text",
+				Type: UpdateMessage,
 
 				Time: time.Unix(int64(e["time"].(float64)), 0),
 
@@ -102,12 +538,20 @@ func (a *APICqhttp) mapToUpdates(m []interface{}) ([]*Update, error) {
 				},
 
 				Message: &Message{
-					ID:      int64(e["message_id"].(float64)),
-					Content: e["raw_message"].(string),
+					ID:       int64(e["message_id"].(float64)),
+					Segments: parseCQSegments(e["raw_message"].(string)),
 				},
 			}
 
 			update.User.UserName = strconv.FormatInt(update.User.ID, 10)
+			update.Message.Content = update.Message.PlainText()
+
+			for _, v := range update.Message.Segments {
+				if v.Type == SegmentReply {
+					update.Message.ReplyTo = a.replyTo(v.MessageID)
+					break
+				}
+			}
 
 			if update.Chat.Type == "private" {
 				update.Chat.ID = int64(e["user_id"].(float64))
@@ -118,19 +562,11 @@ func (a *APICqhttp) mapToUpdates(m []interface{}) ([]*Update, error) {
 			}
 
 			if update.Chat.Type == "group" {
-				m, err := a.API("get_group_list", map[string]interface{}{})
+				title, err := a.groupTitle(update.Chat.ID)
 				if err != nil {
 					return []*Update{}, fmt.Errorf("Get updates: %v", err)
 				}
-
-				gs := m.([]interface{})
-				for _, v := range gs {
-					g := v.(map[string]interface{})
-					if int64(g["group_id"].(float64)) == update.Chat.ID {
-						update.Chat.Title = g["group_name"].(string)
-						break
-					}
-				}
+				update.Chat.Title = title
 
 				u := e["sender"].(map[string]interface{})
 				update.User.NickName = u["nickname"].(string)
@@ -141,67 +577,206 @@ func (a *APICqhttp) mapToUpdates(m []interface{}) ([]*Update, error) {
 				u := e["sender"].(map[string]interface{})
 				update.User.NickName = u["nickname"].(string)
 			}
+		} else if postType == "request" {
+			update = a.requestToUpdate(e)
+			if update == nil {
+				continue
+			}
+		} else if postType == "notice" {
+			var err error
+			update, err = a.noticeToUpdate(e)
+			if err != nil {
+				return []*Update{}, fmt.Errorf("Get updates: %v", err)
+			}
+			if update == nil {
+				continue
+			}
 		} else {
 			continue
 		}
 
-		if update.Message != nil {
-			update.Message.Update = update
-		}
-		if update.Chat != nil {
-			update.Chat.Update = update
-		}
-		if update.User != nil {
-			update.User.Update = update
-		}
+		update.link()
 		us = append(us, update)
 	}
 	return us, nil
 }
 
-// Pull pulls updates and errors into the channels with a given config.
-func (a *APICqhttp) Pull(pc *PullConfig) (UpdateChannel, ErrorChannel) {
-	updates := make(chan *Update)
-	errors := make(chan error)
+func (a *APICqhttp) report(err error) {
+	select {
+	case a.errors <- err:
+	default:
+	}
+}
 
-	var dialer *websocket.Dialer
-	conn, _, err := dialer.Dial(fmt.Sprintf(a.WebsocketEndpoint, a.AccessToken), nil)
-	if err != nil {
-		errors <- err
-		return updates, errors
+func (a *APICqhttp) setConn(conn *websocket.Conn) {
+	a.connMu.Lock()
+	defer a.connMu.Unlock()
+
+	if a.conn == nil {
+		close(a.ready)
 	}
+	a.conn = conn
+}
 
-	go func() {
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				errors <- err
-				time.Sleep(pc.RetryWaitingTime)
+func (a *APICqhttp) dropConn(conn *websocket.Conn) {
+	a.connMu.Lock()
+	defer a.connMu.Unlock()
+
+	if a.conn == conn {
+		a.conn = nil
+		a.ready = make(chan struct{})
+	}
+}
+
+// serve reads events and results of API calls from a connection, API calls are sent through it if api is true.
+func (a *APICqhttp) serve(conn *websocket.Conn, api bool) error {
+	if api {
+		a.setConn(conn)
+		defer a.dropConn(conn)
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		ret := map[string]interface{}{}
+		err = json.Unmarshal(message, &ret)
+		if err != nil {
+			a.report(err)
+			continue
+		}
+
+		if _, ok := ret["post_type"]; !ok {
+			if echo, ok := ret["echo"]; ok {
+				a.pendingMu.Lock()
+				ch, ok := a.pending[fmt.Sprint(echo)]
+				a.pendingMu.Unlock()
+				if ok {
+					ch <- ret
+				}
 				continue
 			}
-			ret := map[string]interface{}{}
-			err = json.Unmarshal(message, &ret)
-			if err != nil {
-				errors <- err
-				time.Sleep(pc.RetryWaitingTime)
-				continue
+		}
+
+		select {
+		case a.events <- ret:
+		default:
+			a.report(errors.New("Event queue is full, dropped an event"))
+		}
+	}
+}
+
+func (a *APICqhttp) checkAuthorization(r *http.Request) bool {
+	if a.AccessToken == "" {
+		return true
+	}
+
+	auth := r.Header.Get("Authorization")
+	if auth == "Bearer "+a.AccessToken || auth == "Token "+a.AccessToken {
+		return true
+	}
+
+	return r.URL.Query().Get("access_token") == a.AccessToken
+}
+
+// reverseAddress returns the address and the path listened for reverse WebSocket, the path is "/ws" by default.
+func (a *APICqhttp) reverseAddress() (string, string) {
+	if a.ReversePath == "" {
+		return a.ReverseListen, "/ws"
+	}
+	return a.ReverseListen, a.ReversePath
+}
+
+// reverseHandler accepts reverse WebSocket connections, connections of the role Event are not used for API calls.
+func (a *APICqhttp) reverseHandler() http.Handler {
+	upgrader := websocket.Upgrader{}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.checkAuthorization(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			a.report(fmt.Errorf("Reverse WebSocket: %v", err))
+			return
+		}
+		defer conn.Close()
+
+		err = a.serve(conn, r.Header.Get("X-Client-Role") != "Event")
+		a.report(fmt.Errorf("Reverse WebSocket: %v", err))
+	})
+}
+
+func (a *APICqhttp) listenReverse(pc *PullConfig) {
+	listen, path := a.reverseAddress()
+	err := handleWebhook(listen, path, a.reverseHandler(), a.errors)
+	if err != nil {
+		a.report(fmt.Errorf("Reverse WebSocket: %v", err))
+	}
+}
+
+func (a *APICqhttp) dial(pc *PullConfig) {
+	wait := pc.RetryWaitingTime
+
+	for {
+		conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf(a.WebsocketEndpoint, a.AccessToken), nil)
+		if err != nil {
+			a.report(fmt.Errorf("WebSocket: %v", err))
+			time.Sleep(wait)
+
+			wait *= 2
+			if wait > maxRetryWaitingTimeCqhttp {
+				wait = maxRetryWaitingTimeCqhttp
 			}
+			continue
+		}
+		wait = pc.RetryWaitingTime
+
+		err = a.serve(conn, true)
+		conn.Close()
+		a.report(fmt.Errorf("WebSocket: %v, reconnecting...", err))
+		time.Sleep(pc.RetryWaitingTime)
+	}
+}
+
+// connect starts the WebSocket connection once, events received before pulling are queued.
+func (a *APICqhttp) connect(pc *PullConfig) {
+	a.once.Do(func() {
+		a.updates = make(chan *Update)
+		a.errors = make(chan error, 100)
+		a.events = make(chan map[string]interface{}, 1000)
+		a.ready = make(chan struct{})
+		a.pending = map[string]chan map[string]interface{}{}
+
+		if a.ReverseListen != "" {
+			go a.listenReverse(pc)
+		} else {
+			go a.dial(pc)
+		}
+	})
+}
+
+// Pull pulls updates and errors into the channels with a given config.
+func (a *APICqhttp) Pull(pc *PullConfig) (UpdateChannel, ErrorChannel) {
+	a.connect(pc)
 
-			m := []interface{}{}
-			m = append(m, ret)
-			us, err := a.mapToUpdates(m)
+	go func() {
+		for e := range a.events {
+			us, err := a.mapToUpdates([]interface{}{e})
 			if err != nil {
-				errors <- err
-				time.Sleep(pc.RetryWaitingTime)
+				a.report(err)
 				continue
 			}
 			for _, u := range us {
-				updates <- u
+				a.updates <- u
 			}
 		}
 	}()
 
-	return updates, errors
+	return a.updates, a.errors
 }
 
 // Push pushes an update and returns it back if existing.
@@ -233,7 +808,13 @@ func (a *APICqhttp) Push(update *Update) (*Update, error) {
 
 	message := ""
 
-	if update.Message.Type == "Audio" {
+	if len(update.Message.Segments) != 0 {
+		var err error
+		message, err = a.renderSegments(update.Message.Segments)
+		if err != nil {
+			return nil, fmt.Errorf("Send message: %v", err)
+		}
+	} else if update.Message.Type == "Audio" {
 		if strings.HasPrefix(update.Message.Content, "http://") || strings.HasPrefix(update.Message.Content, "https://") {
 			message += fmt.Sprintf("[CQ:record,file=%v]", update.Message.Content)
 		} else {
@@ -257,6 +838,10 @@ func (a *APICqhttp) Push(update *Update) (*Update, error) {
 		message += strings.TrimSpace(update.Message.Content)
 	}
 
+	if update.Message.ReplyTo != nil && !strings.HasPrefix(message, "[CQ:reply,") {
+		message = fmt.Sprintf("[CQ:reply,id=%v]", update.Message.ReplyTo.ID) + message
+	}
+
 	m["message"] = message
 
 	msg, err := a.API("send_msg", m)
@@ -269,6 +854,79 @@ func (a *APICqhttp) Push(update *Update) (*Update, error) {
 	return update, nil
 }
 
+// Edit emulates editing by deleting the message and sending the new one, the returned update has a new ID. Content of a message without segments is sent as text whatever its Type is.
+func (a *APICqhttp) Edit(update *Update) (*Update, error) {
+	d := *update
+	d.Type = "Delete"
+	_, err := a.Push(&d)
+	if err != nil {
+		return nil, fmt.Errorf("Edit message: %v", err)
+	}
+
+	m := *update.Message
+	if len(m.Segments) == 0 {
+		m.Type = ""
+	}
+
+	u := *update
+	u.Type = ""
+	u.Message = &m
+	ret, err := a.Push(&u)
+	if err != nil {
+		return nil, fmt.Errorf("Edit message: %v", err)
+	}
+	return ret, nil
+}
+
+// Approve approves or rejects a request of adding a friend or joining a group.
+func (a *APICqhttp) Approve(u *Update, approve bool, reason string) error {
+	if u.Type == UpdateFriendRequest {
+		_, err := a.API("set_friend_add_request", map[string]interface{}{
+			"flag":    u.Request.ID,
+			"approve": approve,
+		})
+		if err != nil {
+			return fmt.Errorf("Approve request: %v", err)
+		}
+		return nil
+	}
+
+	_, err := a.API("set_group_add_request", map[string]interface{}{
+		"flag":     u.Request.ID,
+		"sub_type": u.Request.SubType,
+		"approve":  approve,
+		"reason":   reason,
+	})
+	if err != nil {
+		return fmt.Errorf("Approve request: %v", err)
+	}
+	return nil
+}
+
+// IsChatAdmin checks if the User of an update is the owner or an admin of the group.
+func (a *APICqhttp) IsChatAdmin(u *Update) (bool, error) {
+	if u.Chat == nil || u.Chat.Type != "group" {
+		return false, nil
+	}
+
+	m, err := a.API("get_group_member_info", map[string]interface{}{
+		"group_id": u.Chat.ID,
+		"user_id":  u.User.ID,
+		"no_cache": true,
+	})
+	if err != nil {
+		return false, fmt.Errorf("Get group member info: %v", err)
+	}
+
+	role, _ := m.(map[string]interface{})["role"].(string)
+	return role == "owner" || role == "admin", nil
+}
+
+// AnswerCallback returns an error since there is no callback query on the platform.
+func (a *APICqhttp) AnswerCallback(u *Update, text string, alert bool) error {
+	return errors.New("Answer callback query: Unsupported by the platform")
+}
+
 // Platform returns a string showing the platform of the bot.
 func (a *APICqhttp) Platform() string {
 	return "QQ"
@@ -288,9 +946,17 @@ func (a *APICqhttp) ParseUserID(u *Update, s string) (int64, error) {
 		return id, nil
 	}
 
+	if strings.HasPrefix(s, "@") {
+		id, err := strconv.ParseInt(s[1:], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid At string: %v", err)
+		}
+		return id, nil
+	}
+
 	return 0, errors.New("Invalid At string")
 }
 
 func (a *APICqhttp) ats(u *User) []string {
-	return []string{fmt.Sprintf("[CQ:at,qq=%v]", u.ID)}
+	return []string{fmt.Sprintf("[CQ:at,qq=%v]", u.ID), fmt.Sprintf("@%v", u.ID)}
 }
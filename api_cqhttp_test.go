@@ -0,0 +1,47 @@
+package botmaid
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCqhttpNoticeToUpdate(t *testing.T) {
+	var calls int64
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		if !strings.HasPrefix(r.URL.Path, "/get_group_list") {
+			t.Errorf("API call: %v, want get_group_list", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"status":"ok","retcode":0,"data":[{"group_id":2,"group_name":"Group"}]}`)
+	}))
+	defer s.Close()
+	a := &APICqhttp{APIEndpoint: s.URL + "/%v?access_token=%v"}
+
+	u, err := a.noticeToUpdate(map[string]interface{}{
+		"notice_type": "group_upload",
+		"group_id":    2.0,
+		"user_id":     1.0,
+	})
+	if u != nil || err != nil {
+		t.Errorf("noticeToUpdate(group_upload) = %+v, %v, want nil", u, err)
+	}
+	if n := atomic.LoadInt64(&calls); n != 0 {
+		t.Errorf("noticeToUpdate(group_upload): %v API calls, want 0", n)
+	}
+
+	u, err = a.noticeToUpdate(map[string]interface{}{
+		"notice_type": "group_increase",
+		"group_id":    2.0,
+		"user_id":     1.0,
+	})
+	if err != nil || u == nil || u.Type != UpdateMemberJoined || u.Chat.Title != "Group" {
+		t.Errorf("noticeToUpdate(group_increase) = %+v, %v", u, err)
+	}
+	if n := atomic.LoadInt64(&calls); n != 1 {
+		t.Errorf("noticeToUpdate(group_increase): %v API calls, want 1", n)
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf16"
 
@@ -18,13 +22,27 @@ import (
 )
 
 // APITelegramBot is a struct stores some basic information of the Telegram Bot API. Please search in official API document for details.
+//
+// If WebhookURL is set, updates are received by a webhook instead of long polling. The webhook server listens on WebhookListen and handles requests on WebhookPath, which is the path of WebhookURL by default. WebhookSecret is checked against the secret token header of every request if it is not empty.
 type APITelegramBot struct {
 	Token  string
 	Offset int64
+
+	WebhookURL    string
+	WebhookListen string
+	WebhookPath   string
+	WebhookSecret string
+
+	mu sync.Mutex
 }
 
 const (
-	endPointAPITelegramBot = "https://api.telegram.org/bot%v/%v"
+	endPointAPITelegramBot      = "https://api.telegram.org/bot%v/%v"
+	maxCaptionLengthTelegramBot = 1024
+)
+
+var (
+	allowedUpdatesTelegramBot = []string{"message", "edited_message", "channel_post", "callback_query", "chat_member", "chat_join_request"}
 )
 
 // API returns the body of an HTTP response to the Telegram Bot API.
@@ -66,6 +84,303 @@ func (a *APITelegramBot) API(end string, m map[string]interface{}) (interface{},
 	return ret["result"], nil
 }
 
+func telegramNickName(u map[string]interface{}) string {
+	nickName, _ := u["first_name"].(string)
+	if s, ok := u["last_name"].(string); ok {
+		nickName += " " + s
+	}
+	return nickName
+}
+
+func telegramUser(f map[string]interface{}) *User {
+	u := &User{
+		ID:       int64(f["id"].(float64)),
+		NickName: telegramNickName(f),
+	}
+	u.UserName, _ = f["username"].(string)
+	return u
+}
+
+// telegramReplyMarkup returns the inline keyboard markup of the buttons.
+func telegramReplyMarkup(kb [][]Button) map[string]interface{} {
+	rows := []interface{}{}
+	for _, r := range kb {
+		row := []interface{}{}
+		for _, b := range r {
+			button := map[string]interface{}{
+				"text": b.Text,
+			}
+			if b.URL != "" {
+				button["url"] = b.URL
+			} else {
+				button["callback_data"] = b.Data
+			}
+			row = append(row, button)
+		}
+		rows = append(rows, row)
+	}
+
+	return map[string]interface{}{
+		"inline_keyboard": rows,
+	}
+}
+
+func telegramChat(c map[string]interface{}) *Chat {
+	chat := &Chat{
+		ID:   int64(c["id"].(float64)),
+		Type: c["type"].(string),
+	}
+	chat.Title, _ = c["title"].(string)
+	return chat
+}
+
+// telegramServiceUpdates converts a service message about members or the title into updates, it returns nil if the message is not one of them.
+func telegramServiceUpdates(id int64, m map[string]interface{}) []*Update {
+	newUpdate := func(t string) *Update {
+		return &Update{
+			ID:   id,
+			Type: t,
+			Time: time.Unix(int64(m["date"].(float64)), 0),
+			Chat: telegramChat(m["chat"].(map[string]interface{})),
+		}
+	}
+
+	f, _ := m["from"].(map[string]interface{})
+	from := func() *User {
+		if f == nil {
+			return nil
+		}
+		return telegramUser(f)
+	}
+
+	us := []*Update{}
+
+	if ms, ok := m["new_chat_members"].([]interface{}); ok {
+		for _, v := range ms {
+			update := newUpdate(UpdateMemberJoined)
+			update.User = telegramUser(v.(map[string]interface{}))
+			if op := from(); op != nil && op.ID != update.User.ID {
+				update.Operator = op
+			}
+			update.link()
+			us = append(us, update)
+		}
+		return us
+	}
+
+	if lm, ok := m["left_chat_member"].(map[string]interface{}); ok {
+		update := newUpdate(UpdateMemberLeft)
+		update.User = telegramUser(lm)
+		if op := from(); op != nil && op.ID != update.User.ID {
+			update.Type = UpdateMemberKicked
+			update.Operator = op
+		}
+		update.link()
+		return append(us, update)
+	}
+
+	if title, ok := m["new_chat_title"].(string); ok {
+		update := newUpdate(UpdateGroupRenamed)
+		update.User = from()
+		update.Chat.Title = title
+		update.Notice = &Notice{
+			Title: title,
+		}
+		update.link()
+		return append(us, update)
+	}
+
+	return nil
+}
+
+// telegramChatMemberUpdate converts a chat member update into an admin_changed update, it returns nil if the admin status is not changed.
+func telegramChatMemberUpdate(id int64, cm map[string]interface{}) *Update {
+	isAdmin := func(k string) bool {
+		m, _ := cm[k].(map[string]interface{})
+		return m["status"] == "administrator" || m["status"] == "creator"
+	}
+
+	if isAdmin("old_chat_member") == isAdmin("new_chat_member") {
+		return nil
+	}
+
+	update := &Update{
+		ID:   id,
+		Type: UpdateAdminChanged,
+		Time: time.Unix(int64(cm["date"].(float64)), 0),
+		Chat: telegramChat(cm["chat"].(map[string]interface{})),
+		User: telegramUser(cm["new_chat_member"].(map[string]interface{})["user"].(map[string]interface{})),
+		Notice: &Notice{
+			Admin: isAdmin("new_chat_member"),
+		},
+	}
+
+	if f, ok := cm["from"].(map[string]interface{}); ok {
+		update.Operator = telegramUser(f)
+	}
+
+	return update
+}
+
+func telegramTextSegments(text string, es []interface{}) []Segment {
+	ss := []Segment{}
+
+	u16 := utf16.Encode([]rune(text))
+	pos := 0
+	for _, v := range es {
+		e := v.(map[string]interface{})
+		offset := int(e["offset"].(float64))
+		length := int(e["length"].(float64))
+		if offset < pos || offset+length > len(u16) {
+			continue
+		}
+
+		part := string(utf16.Decode(u16[offset : offset+length]))
+
+		var seg Segment
+		switch e["type"].(string) {
+		case "mention":
+			seg = Segment{
+				Type: SegmentMention,
+				Text: strings.TrimPrefix(part, "@"),
+			}
+		case "text_mention":
+			user, ok := e["user"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			seg = Segment{
+				Type:   SegmentMention,
+				Text:   telegramNickName(user),
+				UserID: int64(user["id"].(float64)),
+			}
+		case "url":
+			seg = Segment{
+				Type: SegmentLink,
+				Text: part,
+				URL:  part,
+			}
+		case "text_link":
+			seg = Segment{
+				Type: SegmentLink,
+				Text: part,
+			}
+			seg.URL, _ = e["url"].(string)
+		default:
+			continue
+		}
+
+		if offset > pos {
+			ss = append(ss, Segment{
+				Type: SegmentText,
+				Text: string(utf16.Decode(u16[pos:offset])),
+			})
+		}
+		ss = append(ss, seg)
+		pos = offset + length
+	}
+
+	if pos < len(u16) {
+		ss = append(ss, Segment{
+			Type: SegmentText,
+			Text: string(utf16.Decode(u16[pos:])),
+		})
+	}
+
+	return ss
+}
+
+func telegramReplyTo(r map[string]interface{}) *ReplyTo {
+	rt := &ReplyTo{
+		ID: int64(r["message_id"].(float64)),
+	}
+
+	if text, ok := r["text"].(string); ok {
+		rt.Excerpt = excerpt(text)
+	} else if text, ok := r["caption"].(string); ok {
+		rt.Excerpt = excerpt(text)
+	}
+
+	if f, ok := r["from"].(map[string]interface{}); ok {
+		rt.User = &User{
+			ID:       int64(f["id"].(float64)),
+			NickName: telegramNickName(f),
+		}
+		rt.User.UserName, _ = f["username"].(string)
+	}
+
+	return rt
+}
+
+// telegramSegments parses a message of the Telegram Bot API into segments.
+func telegramSegments(m map[string]interface{}) []Segment {
+	ss := []Segment{}
+
+	if r, ok := m["reply_to_message"].(map[string]interface{}); ok {
+		ss = append(ss, Segment{
+			Type:      SegmentReply,
+			MessageID: int64(r["message_id"].(float64)),
+		})
+	}
+
+	fileID := func(key string) string {
+		f, _ := m[key].(map[string]interface{})
+		s, _ := f["file_id"].(string)
+		return s
+	}
+
+	if ps, ok := m["photo"].([]interface{}); ok && len(ps) > 0 {
+		s, _ := ps[len(ps)-1].(map[string]interface{})["file_id"].(string)
+		ss = append(ss, Segment{
+			Type: SegmentImage,
+			File: s,
+		})
+	}
+	for _, k := range []string{"voice", "audio"} {
+		if _, ok := m[k]; ok {
+			ss = append(ss, Segment{
+				Type: SegmentAudio,
+				File: fileID(k),
+			})
+		}
+	}
+	for _, k := range []string{"video", "animation", "video_note"} {
+		if _, ok := m[k]; ok {
+			ss = append(ss, Segment{
+				Type: SegmentVideo,
+				File: fileID(k),
+			})
+		}
+	}
+	if _, ok := m["document"]; ok {
+		if _, ok := m["animation"]; !ok {
+			ss = append(ss, Segment{
+				Type: SegmentFile,
+				File: fileID("document"),
+			})
+		}
+	}
+	if st, ok := m["sticker"].(map[string]interface{}); ok {
+		seg := Segment{
+			Type: SegmentEmoji,
+			File: fileID("sticker"),
+		}
+		seg.Text, _ = st["emoji"].(string)
+		ss = append(ss, seg)
+	}
+
+	if text, ok := m["text"].(string); ok {
+		es, _ := m["entities"].([]interface{})
+		ss = append(ss, telegramTextSegments(text, es)...)
+	}
+	if text, ok := m["caption"].(string); ok {
+		es, _ := m["caption_entities"].([]interface{})
+		ss = append(ss, telegramTextSegments(text, es)...)
+	}
+
+	return ss
+}
+
 func (a *APITelegramBot) mapToUpdates(m []interface{}) ([]*Update, error) {
 	us := []*Update{}
 	for _, v := range m {
@@ -73,22 +388,37 @@ func (a *APITelegramBot) mapToUpdates(m []interface{}) ([]*Update, error) {
 
 		update := &Update{}
 
-		if _, ok := e["message"]; ok {
-			m := e["message"].(map[string]interface{})
-			c := m["chat"].(map[string]interface{})
+		if int64(e["update_id"].(float64)) < a.Offset {
+			continue
+		}
+
+		if int64(e["update_id"].(float64))+1 > a.Offset {
+			a.Offset = int64(e["update_id"].(float64)) + 1
+		}
 
-			if int64(e["update_id"].(float64)) < a.Offset {
+		if m, ok := e["message"].(map[string]interface{}); ok {
+			if ss := telegramServiceUpdates(int64(e["update_id"].(float64)), m); ss != nil {
+				us = append(us, ss...)
 				continue
 			}
+		}
 
-			if int64(e["update_id"].(float64))+1 > a.Offset {
-				a.Offset = int64(e["update_id"].(float64)) + 1
+		key := ""
+		for _, k := range []string{"message", "edited_message", "channel_post"} {
+			if _, ok := e[k]; ok {
+				key = k
+				break
 			}
+		}
+
+		if key != "" {
+			m := e[key].(map[string]interface{})
+			c := m["chat"].(map[string]interface{})
 
 			update = &Update{
 				ID: int64(e["update_id"].(float64)),
 
-				Type: "message_text",
+				Type: UpdateMessage,
 
 				Time: time.Unix(int64(m["date"].(float64)), 0),
 
@@ -98,7 +428,8 @@ func (a *APITelegramBot) mapToUpdates(m []interface{}) ([]*Update, error) {
 				},
 
 				Message: &Message{
-					ID: int64(m["message_id"].(float64)),
+					ID:       int64(m["message_id"].(float64)),
+					Segments: telegramSegments(m),
 				},
 			}
 
@@ -106,55 +437,23 @@ func (a *APITelegramBot) mapToUpdates(m []interface{}) ([]*Update, error) {
 				update.Chat.Title = c["title"].(string)
 			}
 
-			if _, ok := m["text"]; ok {
-				update.Message.Content = m["text"].(string)
-				if _, ok := m["reply_to_message"]; ok {
-					r := m["reply_to_message"].(map[string]interface{})
-					if _, ok := r["from"]; ok {
-						u := r["from"].(map[string]interface{})
-						if _, ok := u["username"]; ok {
-							update.Message.Content = fmt.Sprintf("@%v", r["from"].(map[string]interface{})["username"].(string)) + " " + update.Message.Content
-						}
-					}
-				}
+			if key == "channel_post" {
+				update.Type = UpdateChannelPost
+			}
 
-				if _, ok := m["entities"]; ok {
-					es := m["entities"].([]interface{})
-					for i := len(es) - 1; i >= 0; i-- {
-						e := es[i].(map[string]interface{})
-						if e["type"].(string) != "text_mention" {
-							continue
-						}
-
-						if e["user"] != nil {
-							offset := int(e["offset"].(float64))
-							length := int(e["length"].(float64))
-							user := e["user"].(map[string]interface{})
-
-							nickName := user["first_name"].(string)
-							if _, ok := user["last_name"]; ok {
-								nickName += " " + user["last_name"].(string)
-							}
-							strings.ReplaceAll(nickName, "\\", "\\\\")
-							strings.ReplaceAll(nickName, "'", "\\'")
-							strings.ReplaceAll(nickName, "\"", "\\\"")
-
-							u16 := utf16.Encode([]rune(update.Message.Content))
-							t := append(u16[:offset], utf16.Encode([]rune(fmt.Sprintf("\"<a href=\\\"tg://user?id=%v\\\">%v</a>\"", int64(user["id"].(float64)), nickName)))...)
-							u16 = append(t, u16[offset+length:]...)
-							update.Message.Content = string(utf16.Decode(u16))
-						}
-					}
+			if key == "edited_message" {
+				update.Type = UpdateMessageEdited
+				if d, ok := m["edit_date"].(float64); ok {
+					update.Time = time.Unix(int64(d), 0)
 				}
 			}
 
-			if _, ok := m["sticker"]; ok {
-				s := m["sticker"].(map[string]interface{})
-				if _, ok := s["emoji"]; ok {
-					update.Message.Content = s["emoji"].(string)
-				}
+			if r, ok := m["reply_to_message"].(map[string]interface{}); ok {
+				update.Message.ReplyTo = telegramReplyTo(r)
 			}
 
+			update.Message.Content = update.Message.PlainText()
+
 			if _, ok := m["from"]; ok {
 				f := m["from"].(map[string]interface{})
 
@@ -171,33 +470,205 @@ func (a *APITelegramBot) mapToUpdates(m []interface{}) ([]*Update, error) {
 					update.User.UserName = f["username"].(string)
 				}
 			}
+		} else if q, ok := e["callback_query"].(map[string]interface{}); ok {
+			update = &Update{
+				ID: int64(e["update_id"].(float64)),
+
+				Type: UpdateCallbackQuery,
+
+				Time: time.Now(),
+
+				User: telegramUser(q["from"].(map[string]interface{})),
+
+				Callback: &Callback{
+					ID: q["id"].(string),
+				},
+			}
+			update.Callback.Data, _ = q["data"].(string)
+
+			if m, ok := q["message"].(map[string]interface{}); ok {
+				c := m["chat"].(map[string]interface{})
+				update.Chat = &Chat{
+					ID:   int64(c["id"].(float64)),
+					Type: c["type"].(string),
+				}
+				update.Chat.Title, _ = c["title"].(string)
+
+				update.Message = &Message{
+					ID:       int64(m["message_id"].(float64)),
+					Segments: telegramSegments(m),
+				}
+				update.Message.Content = update.Message.PlainText()
+			}
+		} else if r, ok := e["chat_join_request"].(map[string]interface{}); ok {
+			update = &Update{
+				ID: int64(e["update_id"].(float64)),
+
+				Type: UpdateGroupRequest,
+
+				Time: time.Unix(int64(r["date"].(float64)), 0),
+
+				Chat: telegramChat(r["chat"].(map[string]interface{})),
+				User: telegramUser(r["from"].(map[string]interface{})),
+
+				Request: &Request{
+					SubType: "add",
+				},
+			}
+			update.Request.ID = fmt.Sprintf("%v_%v", update.Chat.ID, update.User.ID)
+			update.Request.Comment, _ = r["bio"].(string)
+		} else if cm, ok := e["chat_member"].(map[string]interface{}); ok {
+			update = telegramChatMemberUpdate(int64(e["update_id"].(float64)), cm)
+			if update == nil {
+				continue
+			}
+		} else {
+			continue
 		}
 
-		if update.Message != nil {
-			update.Message.Update = update
+		update.link()
+		us = append(us, update)
+	}
+	return us, nil
+}
+
+func (a *APITelegramBot) webhookHandler(updates UpdateChannel, errors ErrorChannel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
 		}
-		if update.Chat != nil {
-			update.Chat.Update = update
+
+		if a.WebhookSecret != "" && r.Header.Get("X-Telegram-Bot-Api-Secret-Token") != a.WebhookSecret {
+			w.WriteHeader(http.StatusForbidden)
+			return
 		}
-		if update.User != nil {
-			update.User.Update = update
+
+		raw, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errors <- fmt.Errorf("Webhook: %v", err)
+			return
+		}
+
+		e := map[string]interface{}{}
+		err = json.Unmarshal(raw, &e)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			errors <- fmt.Errorf("Webhook: %v", err)
+			return
+		}
+
+		a.mu.Lock()
+		us, err := a.mapToUpdates([]interface{}{e})
+		a.mu.Unlock()
+		if err != nil {
+			w.WriteHeader(http.StatusOK)
+			errors <- fmt.Errorf("Webhook: %v", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		for _, u := range us {
+			updates <- u
 		}
-		us = append(us, update)
 	}
-	return us, nil
+}
+
+// webhookAddress returns the address and the path listened by the webhook server, which are ":8443" and the path of WebhookURL by default.
+func (a *APITelegramBot) webhookAddress() (string, string, error) {
+	listen := a.WebhookListen
+	if listen == "" {
+		listen = ":8443"
+	}
+
+	path := a.WebhookPath
+	if path == "" {
+		u, err := url.Parse(a.WebhookURL)
+		if err != nil {
+			return "", "", err
+		}
+		path = u.Path
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	return listen, path, nil
+}
+
+func (a *APITelegramBot) pullWebhook(pc *PullConfig) (UpdateChannel, ErrorChannel) {
+	updates := make(chan *Update)
+	errors := make(chan error)
+
+	go func() {
+		listen, path, err := a.webhookAddress()
+		if err != nil {
+			errors <- fmt.Errorf("Webhook: %v", err)
+			return
+		}
+
+		err = handleWebhook(listen, path, a.webhookHandler(updates, errors), errors)
+		if err != nil {
+			errors <- fmt.Errorf("Webhook: %v", err)
+			return
+		}
+
+		m := map[string]interface{}{
+			"url":             a.WebhookURL,
+			"allowed_updates": allowedUpdatesTelegramBot,
+		}
+		if a.WebhookSecret != "" {
+			m["secret_token"] = a.WebhookSecret
+		}
+
+		for {
+			_, err := a.API("setWebhook", m)
+			if err != nil {
+				errors <- err
+				time.Sleep(pc.RetryWaitingTime)
+				continue
+			}
+			break
+		}
+	}()
+
+	return updates, errors
+}
+
+// DeleteWebhook unregisters the webhook so that updates could be pulled by long polling.
+func (a *APITelegramBot) DeleteWebhook() error {
+	_, err := a.API("deleteWebhook", map[string]interface{}{})
+	return err
 }
 
 // Pull pulls updates and errors into the channels with a given config.
 func (a *APITelegramBot) Pull(pc *PullConfig) (UpdateChannel, ErrorChannel) {
+	if a.WebhookURL != "" {
+		return a.pullWebhook(pc)
+	}
+
 	updates := make(chan *Update)
 	errors := make(chan error)
 
 	go func() {
+		for {
+			err := a.DeleteWebhook()
+			if err != nil {
+				errors <- err
+				time.Sleep(pc.RetryWaitingTime)
+				continue
+			}
+			break
+		}
+
 		for {
 			m, err := a.API("getUpdates", map[string]interface{}{
-				"limit":   pc.Limit,
-				"timeout": pc.Timeout,
-				"offset":  a.Offset,
+				"limit":           pc.Limit,
+				"timeout":         pc.Timeout,
+				"offset":          a.Offset,
+				"allowed_updates": allowedUpdatesTelegramBot,
 			})
 			if err != nil {
 				errors <- err
@@ -219,6 +690,204 @@ func (a *APITelegramBot) Pull(pc *PullConfig) (UpdateChannel, ErrorChannel) {
 	return updates, errors
 }
 
+// upload sends a local file with some fields by multipart form, and returns the result like API.
+func (a *APITelegramBot) upload(end string, fields map[string]string, para, path string) (interface{}, error) {
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+
+	for k, v := range fields {
+		w.WriteField(k, v)
+	}
+
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("API %v: %v", end, err)
+	}
+
+	part, err := w.CreateFormFile(para, filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("API %v: %v", end, err)
+	}
+	part.Write(file)
+	w.Close()
+
+	resp, err := http.Post(fmt.Sprintf(endPointAPITelegramBot, a.Token, end), w.FormDataContentType(), buf)
+	if err != nil {
+		return nil, fmt.Errorf("API %v: %v", end, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("API %v: %v", end, err)
+	}
+
+	ret := map[string]interface{}{}
+	err = json.Unmarshal(raw, &ret)
+	if err != nil {
+		return nil, fmt.Errorf("API %v: %v", end, err)
+	}
+
+	if _, ok := ret["ok"]; !ok {
+		return nil, fmt.Errorf("API %v: Unsuccessful request", end)
+	}
+
+	if !ret["ok"].(bool) {
+		return nil, fmt.Errorf("API %v: %v", end, ret["description"].(string))
+	}
+
+	return ret["result"], nil
+}
+
+// sendFile sends a file by URL, file ID or local path.
+func (a *APITelegramBot) sendFile(end, para, file string, m map[string]interface{}) (interface{}, error) {
+	if _, err := os.Stat(file); err == nil {
+		fields := map[string]string{}
+		for k, v := range m {
+			if mv, ok := v.(map[string]interface{}); ok {
+				j, err := json.Marshal(mv)
+				if err != nil {
+					return nil, fmt.Errorf("API %v: %v", end, err)
+				}
+				fields[k] = string(j)
+				continue
+			}
+			fields[k] = fmt.Sprint(v)
+		}
+		return a.upload(end, fields, para, file)
+	}
+
+	m[para] = file
+	return a.API(end, m)
+}
+
+func (a *APITelegramBot) renderSegments(ss []Segment) (string, []Segment, int64) {
+	text := ""
+	files := []Segment{}
+	replyTo := int64(0)
+
+	for _, v := range ss {
+		switch v.Type {
+		case SegmentText:
+			text += html.EscapeString(v.Text)
+		case SegmentMention:
+			if v.UserID != 0 {
+				name := v.Text
+				if name == "" {
+					name = strconv.FormatInt(v.UserID, 10)
+				}
+				text += fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", v.UserID, html.EscapeString(name))
+			} else {
+				text += "@" + html.EscapeString(v.Text)
+			}
+		case SegmentLink:
+			title := v.Text
+			if title == "" {
+				title = v.URL
+			}
+			text += fmt.Sprintf("<a href=\"%v\">%v</a>", html.EscapeString(v.URL), html.EscapeString(title))
+		case SegmentEmoji:
+			if v.File != "" {
+				files = append(files, v)
+			} else {
+				text += html.EscapeString(v.Text)
+			}
+		case SegmentReply:
+			replyTo = v.MessageID
+		case SegmentImage, SegmentAudio, SegmentVideo, SegmentFile:
+			files = append(files, v)
+		}
+	}
+
+	return strings.TrimSpace(text), files, replyTo
+}
+
+// telegramCaptioned checks if the text of a message is sent as the caption of its only file instead of a separate message.
+func telegramCaptioned(text string, files []Segment) bool {
+	return text != "" && len(files) == 1 && files[0].Type != SegmentEmoji && len([]rune(text)) <= maxCaptionLengthTelegramBot
+}
+
+// pushSegments sends the text and the files of a message, the text is the caption of the file if there is only one file, otherwise it is sent before the files. The ID of the message with the text is returned so that it could be edited.
+func (a *APITelegramBot) pushSegments(update *Update) (*Update, error) {
+	text, files, replyTo := a.renderSegments(update.Message.Segments)
+	if replyTo == 0 && update.Message.ReplyTo != nil {
+		replyTo = update.Message.ReplyTo.ID
+	}
+
+	var markup interface{}
+	if len(update.Message.Keyboard) != 0 {
+		markup = telegramReplyMarkup(update.Message.Keyboard)
+	}
+
+	captioned := telegramCaptioned(text, files)
+	if text != "" && !captioned {
+		m := map[string]interface{}{
+			"chat_id":    update.Chat.ID,
+			"text":       text,
+			"parse_mode": "HTML",
+		}
+		if replyTo != 0 {
+			m["reply_to_message_id"] = replyTo
+		}
+		if markup != nil {
+			m["reply_markup"] = markup
+			markup = nil
+		}
+
+		msg, err := a.API("sendMessage", m)
+		if err != nil {
+			return nil, fmt.Errorf("Send text message: %v", err)
+		}
+
+		update.ID = int64(msg.(map[string]interface{})["message_id"].(float64))
+		replyTo = 0
+	}
+
+	for _, v := range files {
+		end, para := "sendDocument", "document"
+		switch v.Type {
+		case SegmentImage:
+			end, para = "sendPhoto", "photo"
+			if strings.HasSuffix(v.File, ".gif") {
+				end, para = "sendAnimation", "animation"
+			}
+		case SegmentAudio:
+			end, para = "sendVoice", "voice"
+		case SegmentVideo:
+			end, para = "sendVideo", "video"
+		case SegmentEmoji:
+			end, para = "sendSticker", "sticker"
+		}
+
+		m := map[string]interface{}{
+			"chat_id": update.Chat.ID,
+		}
+		if captioned {
+			m["caption"] = text
+			m["parse_mode"] = "HTML"
+		}
+		if replyTo != 0 {
+			m["reply_to_message_id"] = replyTo
+		}
+		if markup != nil {
+			m["reply_markup"] = markup
+			markup = nil
+		}
+
+		msg, err := a.sendFile(end, para, v.File, m)
+		if err != nil {
+			return nil, fmt.Errorf("Send %v: %v", v.Type, err)
+		}
+
+		if text == "" || captioned {
+			update.ID = int64(msg.(map[string]interface{})["message_id"].(float64))
+		}
+		replyTo = 0
+	}
+
+	return update, nil
+}
+
 // Push pushes an update and returns it back if existing.
 func (a *APITelegramBot) Push(update *Update) (*Update, error) {
 	if update.Type == "Delete" {
@@ -233,6 +902,10 @@ func (a *APITelegramBot) Push(update *Update) (*Update, error) {
 		return nil, nil
 	}
 
+	if len(update.Message.Segments) != 0 {
+		return a.pushSegments(update)
+	}
+
 	if update.Message.Type == "Image" && strings.HasSuffix(update.Message.Content, ".gif") {
 		method := fmt.Sprintf(endPointAPITelegramBot, a.Token, "sendAnimation")
 
@@ -242,6 +915,9 @@ func (a *APITelegramBot) Push(update *Update) (*Update, error) {
 		ct := "multipart/form-data; boundary=" + w.Boundary()
 
 		_ = w.WriteField("chat_id", strconv.FormatInt(update.Chat.ID, 10))
+		if update.Message.ReplyTo != nil {
+			w.WriteField("reply_to_message_id", strconv.FormatInt(update.Message.ReplyTo.ID, 10))
+		}
 
 		file, err := ioutil.ReadFile(update.Message.Content)
 		if err != nil {
@@ -311,6 +987,9 @@ func (a *APITelegramBot) Push(update *Update) (*Update, error) {
 		ct := "multipart/form-data; boundary=" + w.Boundary()
 
 		w.WriteField("chat_id", strconv.FormatInt(update.Chat.ID, 10))
+		if update.Message.ReplyTo != nil {
+			w.WriteField("reply_to_message_id", strconv.FormatInt(update.Message.ReplyTo.ID, 10))
+		}
 
 		if strings.HasPrefix(update.Message.Content, "http://") || strings.HasPrefix(update.Message.Content, "https://") {
 			w.WriteField(para, update.Message.Content)
@@ -376,6 +1055,9 @@ func (a *APITelegramBot) Push(update *Update) (*Update, error) {
 		ct := "multipart/form-data; boundary=" + w.Boundary()
 
 		w.WriteField("chat_id", strconv.FormatInt(update.Chat.ID, 10))
+		if update.Message.ReplyTo != nil {
+			w.WriteField("reply_to_message_id", strconv.FormatInt(update.Message.ReplyTo.ID, 10))
+		}
 
 		if strings.HasPrefix(update.Message.Content, "http://") || strings.HasPrefix(update.Message.Content, "https://") {
 			w.WriteField("voice", update.Message.Content)
@@ -433,11 +1115,19 @@ func (a *APITelegramBot) Push(update *Update) (*Update, error) {
 		return update, nil
 	}
 
-	msg, err := a.API("sendMessage", map[string]interface{}{
+	m := map[string]interface{}{
 		"chat_id":    update.Chat.ID,
 		"text":       strings.TrimSpace(update.Message.Content),
 		"parse_mode": "HTML",
-	})
+	}
+	if update.Message.ReplyTo != nil {
+		m["reply_to_message_id"] = update.Message.ReplyTo.ID
+	}
+	if len(update.Message.Keyboard) != 0 {
+		m["reply_markup"] = telegramReplyMarkup(update.Message.Keyboard)
+	}
+
+	msg, err := a.API("sendMessage", m)
 	if err != nil {
 		return nil, fmt.Errorf("Send text message: %v", err)
 	}
@@ -447,6 +1137,97 @@ func (a *APITelegramBot) Push(update *Update) (*Update, error) {
 	return update, nil
 }
 
+// Edit edits the text of a text message or the caption of a media message.
+func (a *APITelegramBot) Edit(update *Update) (*Update, error) {
+	text := strings.TrimSpace(update.Message.Content)
+	media := update.Message.Type != "" && update.Message.Type != "Text"
+	if len(update.Message.Segments) != 0 {
+		var files []Segment
+		text, files, _ = a.renderSegments(update.Message.Segments)
+		media = telegramCaptioned(text, files) || (text == "" && len(files) != 0)
+	}
+
+	m := map[string]interface{}{
+		"chat_id":    update.Chat.ID,
+		"message_id": update.ID,
+		"parse_mode": "HTML",
+	}
+	if len(update.Message.Keyboard) != 0 {
+		m["reply_markup"] = telegramReplyMarkup(update.Message.Keyboard)
+	}
+
+	end := "editMessageText"
+	if media {
+		end = "editMessageCaption"
+		m["caption"] = text
+	} else {
+		m["text"] = text
+	}
+
+	_, err := a.API(end, m)
+	if err != nil {
+		return nil, fmt.Errorf("Edit message: %v", err)
+	}
+
+	return update, nil
+}
+
+// Approve approves or declines a request of joining a chat, the reason is ignored.
+func (a *APITelegramBot) Approve(u *Update, approve bool, reason string) error {
+	end := "declineChatJoinRequest"
+	if approve {
+		end = "approveChatJoinRequest"
+	}
+
+	_, err := a.API(end, map[string]interface{}{
+		"chat_id": u.Chat.ID,
+		"user_id": u.User.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("Approve request: %v", err)
+	}
+	return nil
+}
+
+// IsChatAdmin checks if the User of an update is the creator or an administrator of the chat.
+func (a *APITelegramBot) IsChatAdmin(u *Update) (bool, error) {
+	if u.Chat == nil || u.Chat.Type == "private" {
+		return false, nil
+	}
+
+	m, err := a.API("getChatAdministrators", map[string]interface{}{
+		"chat_id": u.Chat.ID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("Get chat administrators: %v", err)
+	}
+
+	for _, v := range m.([]interface{}) {
+		user, _ := v.(map[string]interface{})["user"].(map[string]interface{})
+		if id, ok := user["id"].(float64); ok && int64(id) == u.User.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AnswerCallback answers a callback query with a notification or an alert, the text could be empty.
+func (a *APITelegramBot) AnswerCallback(u *Update, text string, alert bool) error {
+	m := map[string]interface{}{
+		"callback_query_id": u.Callback.ID,
+		"show_alert":        alert,
+	}
+	if text != "" {
+		m["text"] = text
+	}
+
+	_, err := a.API("answerCallbackQuery", m)
+	if err != nil {
+		return fmt.Errorf("Answer callback query: %v", err)
+	}
+	return nil
+}
+
 // Platform returns a string showing the platform of the bot.
 func (a *APITelegramBot) Platform() string {
 	return "Telegram"
@@ -469,7 +1250,11 @@ func (a *APITelegramBot) ParseUserID(u *Update, s string) (int64, error) {
 	}
 
 	if strings.HasPrefix(s, "@") {
-		s = u.Bot.BotMaid.Redis.HGet("telegramUsers", s[1:]).Val()
+		if id, err := strconv.ParseInt(s[1:], 10, 64); err == nil {
+			return id, nil
+		}
+
+		s, _ = u.Bot.BotMaid.Store.HGet("telegramUsers", s[1:])
 
 		id, err := strconv.ParseInt(s, 10, 64)
 		if err != nil {
@@ -482,5 +1267,5 @@ func (a *APITelegramBot) ParseUserID(u *Update, s string) (int64, error) {
 }
 
 func (a *APITelegramBot) ats(u *User) []string {
-	return []string{fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", u.ID, u.NickName), fmt.Sprintf("@%v", u.UserName)}
+	return []string{fmt.Sprintf("<a href=\"tg://user?id=%v\">%v</a>", u.ID, u.NickName), fmt.Sprintf("@%v", u.UserName), fmt.Sprintf("@%v", u.ID)}
 }
@@ -0,0 +1,31 @@
+package botmaid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTelegramCaptioned(t *testing.T) {
+	image := Segment{Type: SegmentImage, File: "a.jpg"}
+	sticker := Segment{Type: SegmentEmoji, File: "s"}
+
+	tests := []struct {
+		text  string
+		files []Segment
+		want  bool
+	}{
+		{"hi", []Segment{image}, true},
+		{"", []Segment{image}, false},
+		{"hi", []Segment{}, false},
+		{"hi", []Segment{image, image}, false},
+		{"hi", []Segment{sticker}, false},
+		{strings.Repeat("a", maxCaptionLengthTelegramBot), []Segment{image}, true},
+		{strings.Repeat("a", maxCaptionLengthTelegramBot+1), []Segment{image}, false},
+	}
+
+	for _, tt := range tests {
+		if got := telegramCaptioned(tt.text, tt.files); got != tt.want {
+			t.Errorf("telegramCaptioned(%q, %v) = %v, want %v", excerpt(tt.text), tt.files, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package botmaid
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Types of positional arguments.
+//
+// ArgUser is a mention or an ID of a user, which is parsed into the ID by ParseUserID of the API. ArgRest takes all the remaining arguments joined by spaces.
+const (
+	ArgString   = "string"
+	ArgInt      = "int"
+	ArgDuration = "duration"
+	ArgUser     = "user"
+	ArgChat     = "chat"
+	ArgEnum     = "enum"
+	ArgRest     = "rest"
+)
+
+// Arg declares a positional argument of a command, the parsed value is saved in Params of the message by Name.
+//
+// Enum is the valid values of an enum argument. Optional arguments could be omitted, they should follow the required ones, and an ArgRest argument should be the last one.
+type Arg struct {
+	Name     string
+	Type     string
+	Enum     []string
+	Optional bool
+}
+
+func (a *Arg) parse(u *Update, s string) (interface{}, error) {
+	switch a.Type {
+	case ArgInt, ArgChat:
+		return strconv.ParseInt(s, 10, 64)
+	case ArgDuration:
+		return time.ParseDuration(s)
+	case ArgUser:
+		id, err := (*u.Bot.API).ParseUserID(u, s)
+		if err != nil {
+			return strconv.ParseInt(s, 10, 64)
+		}
+		return id, nil
+	case ArgEnum:
+		if !Contains(a.Enum, s) {
+			return nil, errors.New("Invalid value")
+		}
+	}
+	return s, nil
+}
+
+func argsUsage(name string, args []Arg) string {
+	s := name
+	for _, a := range args {
+		v := a.Name
+		if a.Type == ArgEnum {
+			v = strings.Join(a.Enum, "|")
+		}
+		if a.Type == ArgRest {
+			v += "..."
+		}
+
+		if a.Optional {
+			s += " [" + v + "]"
+		} else {
+			s += " <" + v + ">"
+		}
+	}
+	return s
+}
+
+// parseArgs parses the positional arguments in the flags into Params of the message of an update.
+func (bm *BotMaid) parseArgs(u *Update, args []Arg, f *pflag.FlagSet) error {
+	u.Message.Params = map[string]interface{}{}
+	ss := []string{}
+	if len(f.Args()) > 1 {
+		ss = f.Args()[1:]
+	}
+
+	for i, a := range args {
+		if i >= len(ss) {
+			if a.Optional {
+				continue
+			}
+			return fmt.Errorf("Missing argument %v", a.Name)
+		}
+
+		s := ss[i]
+		if a.Type == ArgRest {
+			s = strings.Join(ss[i:], " ")
+			ss = ss[:i+1]
+		}
+
+		v, err := a.parse(u, s)
+		if err != nil {
+			return fmt.Errorf("Invalid argument %v: %v", a.Name, err)
+		}
+		u.Message.Params[a.Name] = v
+	}
+
+	if len(ss) > len(args) {
+		return errors.New("Too many arguments")
+	}
+	return nil
+}
+
+// replyInvalidArgs replies invalidParameters with the usage of a command.
+func (bm *BotMaid) replyInvalidArgs(u *Update, name string, c *Command) {
+	if u.User == nil {
+		return
+	}
+
+	usage := c.Help.Usage
+	if usage == "" {
+		usage = argsUsage(name, c.Args)
+	}
+	bm.Reply(u, fmt.Sprintf(bm.Words["invalidParameters"], bm.At(u.User), name)+"\n"+usage)
+}
+
+// IntParam returns the int, duration, user or chat parameter by its name, or 0 if it is not set.
+func (m *Message) IntParam(name string) int64 {
+	switch v := m.Params[name].(type) {
+	case int64:
+		return v
+	case time.Duration:
+		return int64(v)
+	}
+	return 0
+}
+
+// DurationParam returns the duration parameter by its name, or 0 if it is not set.
+func (m *Message) DurationParam(name string) time.Duration {
+	d, _ := m.Params[name].(time.Duration)
+	return d
+}
+
+// StringParam returns the string, enum or rest parameter by its name, or an empty string if it is not set.
+func (m *Message) StringParam(name string) string {
+	s, _ := m.Params[name].(string)
+	return s
+}
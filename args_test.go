@@ -0,0 +1,96 @@
+package botmaid_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestArgs(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.Conf.Ban.ReplyLoop = 0
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, fmt.Sprintf("%v %v", u.Message.IntParam("A")+u.Message.IntParam("B"), u.Message.StringParam("NOTE")))
+			return true
+		},
+		Args: []botmaid.Arg{
+			{Name: "A", Type: botmaid.ArgInt},
+			{Name: "B", Type: botmaid.ArgUser},
+			{Name: "NOTE", Type: botmaid.ArgEnum, Enum: []string{"x", "y"}, Optional: true},
+		},
+		Help: &botmaid.Help{
+			Menu:  "add",
+			Names: []string{"add"},
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			return false
+		},
+		Help: &botmaid.Help{
+			Menu:  "verset",
+			Names: []string{"verset"},
+		},
+		Subcommands: h.BotMaid.VersetSubcommands(),
+	})
+	addMaster(h, 1)
+
+	expectReplies(t, "/add 1 2", h.Say("/add 1 2"), "3 ")
+	expectReplies(t, "/add 1 @2 y", h.Say("/add 1 @2 y"), "3 y")
+
+	for _, s := range []string{"/add 1", "/add 1 x", "/add 1 2 z", "/add 1 2 x y"} {
+		got := h.Say(s)
+		if len(got) != 1 || got[0] != "@user, the parameters of the command \"add\" is invalid.\nadd <A> <B> [x|y]" {
+			t.Errorf("%q: replies %q, want invalidParameters with the usage", s, got)
+		}
+	}
+
+	got := h.Say("/verset log add")
+	if len(got) != 1 || !strings.HasPrefix(got[0], `@user, the parameters of the command "verset log add" is invalid.`) {
+		t.Errorf("/verset log add: replies %q, want invalidParameters", got)
+	}
+}
+
+func TestBuiltinCommandsArgs(t *testing.T) {
+	for _, declared := range []bool{true, false} {
+		h := newHarness(t)
+		h.BotMaid.Conf.Ban.ReplyLoop = 0
+		h.BotMaid.SubEntries = []string{"log"}
+		addMaster(h, 1)
+
+		master := &botmaid.Command{
+			Do: h.BotMaid.MasterCommandDo,
+			Help: &botmaid.Help{
+				Menu:  "master",
+				Names: []string{"master"},
+			},
+		}
+		subscribe := &botmaid.Command{
+			Do: h.BotMaid.SubscribeCommandDo,
+			Help: &botmaid.Help{
+				Menu:  "subscribe",
+				Names: []string{"subscribe"},
+			},
+		}
+		if declared {
+			master.Args = h.BotMaid.MasterCommandArgs()
+			subscribe.Args = h.BotMaid.SubscribeCommandArgs()
+		}
+		h.BotMaid.AddCommand(master)
+		h.BotMaid.AddCommand(subscribe)
+
+		expectReplies(t, "/master @2", h.Say("/master @2"), "@user, the user 2 has been registered as master.")
+		if ok, _ := h.Store.SIsMember("master_"+h.Bot.ID, 2); !ok {
+			t.Errorf("declared %v: 2 is not a master", declared)
+		}
+		expectReplies(t, "/subscribe log", h.Say("/subscribe log"), `"log" has been subscibed on this Chat.`)
+
+		if !declared {
+			expectReplies(t, "/subscribe x", h.Say("/subscribe x"), `These entries can be subscibed: "log"`)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package botmaid
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type banInfo struct {
+	Until  time.Time
+	Reason string
+}
+
+func banKey(botID string, user bool) string {
+	if user {
+		return "banUser_" + botID
+	}
+	return "ban_" + botID
+}
+
+func banInfoField(user bool, id int64) string {
+	if user {
+		return fmt.Sprintf("user_%v", id)
+	}
+	return fmt.Sprintf("chat_%v", id)
+}
+
+func (bm *BotMaid) ban(botID string, user bool, id int64, d time.Duration, reason string) {
+	info := &banInfo{
+		Reason: reason,
+	}
+	if d > 0 {
+		info.Until = time.Now().Add(d)
+	}
+
+	j, _ := json.Marshal(info)
+	bm.Store.HSet("banInfo_"+botID, banInfoField(user, id), string(j))
+	bm.Store.SAdd(banKey(botID, user), id)
+}
+
+func (bm *BotMaid) unban(botID string, user bool, id int64) bool {
+	is, _ := bm.Store.SIsMember(banKey(botID, user), id)
+	bm.Store.SRem(banKey(botID, user), id)
+	bm.Store.HDel("banInfo_"+botID, banInfoField(user, id))
+	return is
+}
+
+func (bm *BotMaid) getBanInfo(botID string, user bool, id int64) *banInfo {
+	info := &banInfo{}
+	s, _ := bm.Store.HGet("banInfo_"+botID, banInfoField(user, id))
+	json.Unmarshal([]byte(s), info)
+	return info
+}
+
+// isBanned checks if a chat or a user has been banned, expired bans and bans without ban info are lifted.
+func (bm *BotMaid) isBanned(botID string, user bool, id int64) bool {
+	is, _ := bm.Store.SIsMember(banKey(botID, user), id)
+	if !is {
+		return false
+	}
+
+	// Chats banned by the former reply loop detector have no ban info, and the bans were never enforced.
+	if s, _ := bm.Store.HGet("banInfo_"+botID, banInfoField(user, id)); s == "" {
+		bm.Store.SRem(banKey(botID, user), id)
+		return false
+	}
+
+	info := bm.getBanInfo(botID, user, id)
+	if !info.Until.IsZero() && time.Now().After(info.Until) {
+		bm.unban(botID, user, id)
+		return false
+	}
+
+	return true
+}
+
+// IsUserBanned checks if a user has been banned.
+func (bm *BotMaid) IsUserBanned(u *User) bool {
+	return bm.isBanned(u.Update.Bot.ID, true, u.ID)
+}
+
+// BanMiddleware drops the updates from banned chats and users except masters. It is used by default.
+func (bm *BotMaid) BanMiddleware(next Handler) Handler {
+	return func(u *Update) bool {
+		if u.User != nil && bm.IsMaster(u.User) {
+			return next(u)
+		}
+
+		if u.Chat != nil && bm.IsBanned(u.Chat) {
+			return false
+		}
+		if u.User != nil && bm.IsUserBanned(u.User) {
+			return false
+		}
+
+		return next(u)
+	}
+}
+
+func (bm *BotMaid) parseBanTarget(u *Update, user bool, s string) (int64, error) {
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return id, nil
+	}
+	if !user {
+		return 0, fmt.Errorf("Invalid chat ID: %v", s)
+	}
+	return (*u.Bot.API).ParseUserID(u, s)
+}
+
+// BanCommandDo bans a chat or a user for a duration by -t, or permanently. It is refused to users other than masters.
+func (bm *BotMaid) BanCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if !bm.requireMaster(u) {
+		return true
+	}
+
+	if len(f.Args()) < 2 {
+		return false
+	}
+
+	user, _ := f.GetBool("user")
+	d, err := f.GetDuration("time")
+	if err != nil {
+		return false
+	}
+
+	id, err := bm.parseBanTarget(u, user, f.Args()[1])
+	if err != nil {
+		bm.Reply(u, fmt.Sprintf(bm.Words["invalidBanTarget"], bm.At(u.User), f.Args()[1]))
+		return true
+	}
+
+	reason := strings.Join(f.Args()[2:], " ")
+	bm.ban(u.Bot.ID, user, id, d, reason)
+
+	until := ""
+	if d > 0 {
+		until = fmt.Sprintf(bm.Words["banUntil"], time.Now().Add(d).Format("2006-01-02 15:04:05"))
+	}
+	bm.Reply(u, fmt.Sprintf(bm.Words["banned"], f.Args()[1], until))
+	return true
+}
+
+func (bm *BotMaid) BanCommandHelpSetFlag(f *pflag.FlagSet) {
+	f.BoolP("user", "u", false, bm.Words["banUserHelp"])
+	f.DurationP("time", "t", 0, bm.Words["banTimeHelp"])
+}
+
+// UnbanCommandDo lifts the ban of a chat or a user, only masters could use it.
+func (bm *BotMaid) UnbanCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if !bm.requireMaster(u) {
+		return true
+	}
+
+	if len(f.Args()) != 2 {
+		return false
+	}
+
+	user, _ := f.GetBool("user")
+
+	id, err := bm.parseBanTarget(u, user, f.Args()[1])
+	if err != nil {
+		bm.Reply(u, fmt.Sprintf(bm.Words["invalidBanTarget"], bm.At(u.User), f.Args()[1]))
+		return true
+	}
+
+	if !bm.unban(u.Bot.ID, user, id) {
+		bm.Reply(u, fmt.Sprintf(bm.Words["notBanned"], f.Args()[1]))
+		return true
+	}
+
+	bm.Reply(u, fmt.Sprintf(bm.Words["unbanned"], f.Args()[1]))
+	return true
+}
+
+func (bm *BotMaid) UnbanCommandHelpSetFlag(f *pflag.FlagSet) {
+	f.BoolP("user", "u", false, bm.Words["banUserHelp"])
+}
+
+// BanlistCommandDo lists the banned chats and users with their reasons to masters.
+func (bm *BotMaid) BanlistCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if !bm.requireMaster(u) {
+		return true
+	}
+
+	lines := []string{}
+	for _, user := range []bool{false, true} {
+		ids, _ := bm.Store.SMembers(banKey(u.Bot.ID, user))
+		for _, v := range ids {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || !bm.isBanned(u.Bot.ID, user, id) {
+				continue
+			}
+
+			kind := bm.Words["banChat"]
+			if user {
+				kind = bm.Words["banUser"]
+			}
+
+			info := bm.getBanInfo(u.Bot.ID, user, id)
+			line := fmt.Sprintf("\n%v %v", kind, id)
+			if !info.Until.IsZero() {
+				line += fmt.Sprintf(bm.Words["banUntil"], info.Until.Format("2006-01-02 15:04:05"))
+			}
+			if info.Reason != "" {
+				line += ": " + info.Reason
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) == 0 {
+		bm.Reply(u, bm.Words["noBan"])
+		return true
+	}
+
+	sort.Strings(lines)
+	bm.Reply(u, fmt.Sprintf(bm.Words["banList"], strings.Join(lines, "")))
+	return true
+}
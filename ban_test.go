@@ -0,0 +1,80 @@
+package botmaid_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/the-cattail/botmaid"
+)
+
+func TestBanExpiry(t *testing.T) {
+	h := newHarness(t)
+	addEcho(h)
+	addMaster(h, 1)
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do:         h.BotMaid.BanCommandDo,
+		Permission: botmaid.RoleOwner,
+		Help: &botmaid.Help{
+			Menu:    "ban",
+			Names:   []string{"ban"},
+			SetFlag: h.BotMaid.BanCommandHelpSetFlag,
+		},
+	})
+
+	got := h.Say("/ban -u -t 100ms 2")
+	if len(got) != 1 || !strings.HasPrefix(got[0], "2 has been banned until") {
+		t.Fatalf("/ban: replies %q", got)
+	}
+
+	expectReplies(t, "/echo by a banned user", sayAs(h, 2, "/echo hi"))
+	expectReplies(t, "/echo by another user", sayAs(h, 3, "/echo hi"), "hi")
+
+	time.Sleep(150 * time.Millisecond)
+	expectReplies(t, "/echo after the ban expires", sayAs(h, 2, "/echo hi"), "hi")
+}
+
+func TestReplyLoopBan(t *testing.T) {
+	h := newHarness(t)
+	addEcho(h)
+	h.BotMaid.Conf.Ban.ReplyLoop = 100 * time.Millisecond
+
+	for i := 0; i < 5; i++ {
+		expectReplies(t, "/echo hi", h.Say("/echo hi"), "hi")
+	}
+	expectReplies(t, "/echo in a reply loop", h.Say("/echo hi"))
+
+	time.Sleep(150 * time.Millisecond)
+	expectReplies(t, "/echo after the ban expires", h.Say("/echo hi"), "hi")
+}
+
+func TestLegacyBan(t *testing.T) {
+	h := newHarness(t)
+	addEcho(h)
+	h.Store.SAdd("ban_"+h.Bot.ID, 1)
+
+	expectReplies(t, "/echo in a chat banned without ban info", h.Say("/echo hi"), "hi")
+	if ok, _ := h.Store.SIsMember("ban_"+h.Bot.ID, 1); ok {
+		t.Error("the ban without ban info is not dropped")
+	}
+}
+
+// TestReplyLoopConcurrently dispatches updates from many goroutines, run it with -race.
+func TestReplyLoopConcurrently(t *testing.T) {
+	h := newHarness(t)
+	addEcho(h)
+
+	var wg sync.WaitGroup
+	for i := int64(1); i <= 20; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			u := h.NewMessage("/echo hi")
+			u.Chat.ID = id % 4
+			u.Bot = h.Bot
+			h.BotMaid.Dispatch(u)
+		}(i)
+	}
+	wg.Wait()
+}
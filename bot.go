@@ -2,7 +2,6 @@ package botmaid
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 )
@@ -18,14 +17,33 @@ type Bot struct {
 	BotMaid *BotMaid
 }
 
+// AddBot adds a bot with an API into the BotMaid, it is useful for APIs not configured in the config file.
+func (bm *BotMaid) AddBot(id string, api API, self *User) *Bot {
+	b := &Bot{
+		ID:      id,
+		API:     &api,
+		Self:    self,
+		BotMaid: bm,
+	}
+
+	if self.Update == nil {
+		self.Update = &Update{}
+	}
+	self.Update.Bot = b
+
+	bm.Bots[id] = b
+	return b
+}
+
 // IsMaster checks if a user is master of the bot.
 func (bm *BotMaid) IsMaster(u *User) bool {
-	return bm.Redis.SIsMember("master_"+u.Update.Bot.ID, u.ID).Val()
+	is, _ := bm.Store.SIsMember("master_"+u.Update.Bot.ID, u.ID)
+	return is
 }
 
-// IsBanned checks if a user has been banned.
+// IsBanned checks if a chat has been banned.
 func (bm *BotMaid) IsBanned(c *Chat) bool {
-	return bm.Redis.SIsMember("ban_"+c.Update.Bot.ID, c.ID).Val()
+	return bm.isBanned(c.Update.Bot.ID, false, c.ID)
 }
 
 // At returns a string to mention someone in a message.
@@ -33,12 +51,16 @@ func (bm *BotMaid) At(u *User) string {
 	return (*u.Update.Bot.API).ats(u)[0]
 }
 
-// BeAt checks if a message of an update is mentioning the bot.
+// BeAt checks if a message of an update is mentioning the bot, replying to a message of the bot is also mentioning it.
 func (bm *BotMaid) BeAt(u *Update) bool {
 	if bm.extractCommand(u) != "" {
 		return false
 	}
 
+	if rt := u.Message.ReplyTo; rt != nil && rt.User != nil && rt.User.ID == u.Bot.Self.ID {
+		return true
+	}
+
 	for _, v := range (*u.Bot.API).ats(u.Bot.Self) {
 		if strings.Contains(u.Message.Content, v) {
 			return true
@@ -48,14 +70,18 @@ func (bm *BotMaid) BeAt(u *Update) bool {
 	return false
 }
 
+// antiReplyLoop bans a chat for Ban.ReplyLoop in the config once 5 messages are replied to it in a second, 0 disables it.
 func (bm *BotMaid) antiReplyLoop(u *Update) {
+	bm.historyMu.Lock()
+	defer bm.historyMu.Unlock()
+
 	now := time.Now()
 	for len(bm.history[u.Chat.ID]) > 0 && now.Sub(bm.history[u.Chat.ID][0]) > time.Second {
 		bm.history[u.Chat.ID] = bm.history[u.Chat.ID][1:]
 	}
 	bm.history[u.Chat.ID] = append(bm.history[u.Chat.ID], now)
-	if len(bm.history[u.Chat.ID]) >= 5 {
-		bm.Redis.SAdd(fmt.Sprintf("ban_%v", u.Bot.ID), u.Chat.ID)
+	if len(bm.history[u.Chat.ID]) >= 5 && bm.Conf.Ban.ReplyLoop > 0 {
+		bm.ban(u.Bot.ID, false, u.Chat.ID, bm.Conf.Ban.ReplyLoop, bm.Words["replyLoop"])
 	}
 }
 
@@ -68,6 +94,25 @@ func (bm *BotMaid) Reply(u *Update, s string) (*Update, error) {
 			Content: s,
 		},
 		Chat: u.Chat,
+		Bot:  u.Bot,
+	})
+}
+
+// QuoteReply replies a message back quoting the message of the update.
+func (bm *BotMaid) QuoteReply(u *Update, s string) (*Update, error) {
+	bm.antiReplyLoop(u)
+
+	return (*u.Bot.API).Push(&Update{
+		Message: &Message{
+			Content: s,
+			ReplyTo: &ReplyTo{
+				ID:      u.Message.ID,
+				User:    u.User,
+				Excerpt: excerpt(u.Message.PlainText()),
+			},
+		},
+		Chat: u.Chat,
+		Bot:  u.Bot,
 	})
 }
 
@@ -82,12 +127,54 @@ func (bm *BotMaid) ReplyType(u *Update, s, t string) (*Update, error) {
 				Content: s,
 			},
 			Chat: u.Chat,
+			Bot:  u.Bot,
 		})
 	}
 
 	return nil, errors.New("Invalid type of message")
 }
 
+// ReplySegments replies a message made up of segments back.
+func (bm *BotMaid) ReplySegments(u *Update, ss ...Segment) (*Update, error) {
+	bm.antiReplyLoop(u)
+
+	return (*u.Bot.API).Push(&Update{
+		Message: &Message{
+			Segments: ss,
+		},
+		Chat: u.Chat,
+		Bot:  u.Bot,
+	})
+}
+
+// Edit changes the text of a message pushed before, the returned update should be used for later editing since the ID may change on some platforms.
+//
+// If the message is made up of segments, its text segments are replaced by s and the others such as images are kept.
+func (bm *BotMaid) Edit(u *Update, s string) (*Update, error) {
+	m := *u.Message
+	m.Content = s
+	if len(m.Segments) != 0 {
+		ss := []Segment{}
+		for _, v := range m.Segments {
+			switch {
+			case v.Type == SegmentText, v.Type == SegmentMention, v.Type == SegmentLink:
+			case v.Type == SegmentEmoji && v.File == "":
+			default:
+				ss = append(ss, v)
+			}
+		}
+		m.Segments = append(ss, Segment{
+			Type: SegmentText,
+			Text: s,
+		})
+	}
+
+	uu := *u
+	uu.Message = &m
+	return (*u.Bot.API).Edit(&uu)
+}
+
+// Delete deletes a message.
 func (bm *BotMaid) Delete(u *Update) (*Update, error) {
 	uu := *u
 	uu.Type = "Delete"
@@ -0,0 +1,35 @@
+package botmaid_test
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestMentionByReply(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, "yes?")
+			return true
+		},
+		Triggers: []botmaid.Trigger{
+			{Type: botmaid.TriggerMention},
+		},
+	})
+
+	u := h.NewMessage("hi")
+	u.Message.ReplyTo = &botmaid.ReplyTo{
+		ID:   1,
+		User: &botmaid.User{ID: h.Bot.Self.ID},
+	}
+	expectReplies(t, "hi in reply to the bot", texts(h.Send(u)), "yes?")
+
+	u = h.NewMessage("hi")
+	u.Message.ReplyTo = &botmaid.ReplyTo{
+		ID:   1,
+		User: &botmaid.User{ID: 2},
+	}
+	expectReplies(t, "hi in reply to another user", texts(h.Send(u)))
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -21,10 +22,33 @@ type botmaidRedisConfig struct {
 	Database int
 }
 
+type botmaidStoreConfig struct {
+	Type string
+	Path string
+}
+
+type botmaidPermissionConfig struct {
+	ChatAdminTTL time.Duration
+}
+
+type botmaidSessionConfig struct {
+	Timeout time.Duration
+	Cancel  []string
+}
+
+type botmaidBanConfig struct {
+	ReplyLoop time.Duration
+}
+
 type botMaidConfig struct {
-	Redis         botmaidRedisConfig
-	Log           bool
-	CommandPrefix []string
+	Store              botmaidStoreConfig
+	Permission         botmaidPermissionConfig
+	Session            botmaidSessionConfig
+	Ban                botmaidBanConfig
+	Redis              botmaidRedisConfig
+	Log                bool
+	CommandPrefix      []string
+	SuggestionDistance int
 }
 
 // BotMaid includes a slice of Bot and some methods to use them.
@@ -33,17 +57,25 @@ type BotMaid struct {
 
 	Conf *botMaidConfig
 
-	Redis *redis.Client
+	Store Store
+
+	Routes      []*Route
+	Middlewares []Middleware
+	Commands    CommandSlice
+	Callbacks   []*CallbackHandler
 
-	Commands CommandSlice
-	Timers   []*Timer
-	Helps    []*Help
+	SessionHandlers map[string]*SessionHandler
+
+	Timers []*Timer
+	Helps  []*Help
 
 	Words      map[string]string
 	SubEntries []string
 
-	respTime time.Time
-	history  map[int64][]time.Time
+	respTime  time.Time
+	history   map[int64][]time.Time
+	historyMu sync.Mutex
+	webhooks  map[string]string
 }
 
 func (bm *BotMaid) readBotConfig(conf *toml.Tree, section string) error {
@@ -70,6 +102,25 @@ func (bm *BotMaid) readBotConfig(conf *toml.Tree, section string) error {
 		if s, ok := conf.Get(section + ".WebsocketEndpoint").(string); ok {
 			q.WebsocketEndpoint = s
 		}
+		if s, ok := conf.Get(section + ".ReverseListen").(string); ok {
+			q.ReverseListen = s
+		}
+		if s, ok := conf.Get(section + ".ReversePath").(string); ok {
+			q.ReversePath = s
+		}
+		if f, ok := conf.Get(section + ".APIOverWebsocket").(bool); ok {
+			q.APIOverWebsocket = f
+		}
+		if a, ok := conf.Get(section + ".APITimeout").(int64); ok {
+			q.APITimeout = time.Duration(a) * time.Second
+		}
+
+		if q.ReverseListen != "" {
+			listen, path := q.reverseAddress()
+			if err := bm.claimWebhook(section, listen, path); err != nil {
+				return err
+			}
+		}
 
 		for {
 			m, err := q.API("get_login_info", map[string]interface{}{})
@@ -101,6 +152,28 @@ func (bm *BotMaid) readBotConfig(conf *toml.Tree, section string) error {
 		if s, ok := conf.Get(section + ".Token").(string); ok {
 			t.Token = s
 		}
+		if s, ok := conf.Get(section + ".WebhookURL").(string); ok {
+			t.WebhookURL = s
+		}
+		if s, ok := conf.Get(section + ".WebhookListen").(string); ok {
+			t.WebhookListen = s
+		}
+		if s, ok := conf.Get(section + ".WebhookPath").(string); ok {
+			t.WebhookPath = s
+		}
+		if s, ok := conf.Get(section + ".WebhookSecret").(string); ok {
+			t.WebhookSecret = s
+		}
+
+		if t.WebhookURL != "" {
+			listen, path, err := t.webhookAddress()
+			if err != nil {
+				return fmt.Errorf("Init botmaid: Webhook of %v: %v", section, err)
+			}
+			if err := bm.claimWebhook(section, listen, path); err != nil {
+				return err
+			}
+		}
 
 		for {
 			m, err := t.API("getMe", map[string]interface{}{})
@@ -130,6 +203,42 @@ func (bm *BotMaid) readBotConfig(conf *toml.Tree, section string) error {
 			break
 		}
 		*b.API = t
+	} else if botType == "Console" {
+		c := &APIConsole{
+			UserID:   1,
+			UserName: "user",
+		}
+
+		if id, ok := conf.Get(section + ".UserID").(int64); ok {
+			c.UserID = id
+		}
+		if s, ok := conf.Get(section + ".UserName").(string); ok {
+			c.UserName = s
+		}
+		c.ChatID = c.UserID
+		if id, ok := conf.Get(section + ".ChatID").(int64); ok {
+			c.ChatID = id
+		}
+		if s, ok := conf.Get(section + ".ChatType").(string); ok {
+			c.ChatType = s
+		}
+
+		b.Self = &User{
+			ID:       0,
+			UserName: "botmaid",
+			NickName: "BotMaid",
+			Update: &Update{
+				Bot: b,
+			},
+		}
+		if s, ok := conf.Get(section + ".NickName").(string); ok {
+			b.Self.NickName = s
+		}
+		if s, ok := conf.Get(section + ".BotUserName").(string); ok {
+			b.Self.UserName = s
+		}
+
+		*b.API = c
 	} else {
 		return fmt.Errorf("Init botmaid: Unknown type of %v", section)
 	}
@@ -137,7 +246,7 @@ func (bm *BotMaid) readBotConfig(conf *toml.Tree, section string) error {
 	if ms, ok := conf.Get(section + ".Master").([]interface{}); ok {
 		for _, v := range ms {
 			if id, ok := v.(int64); ok {
-				bm.Redis.SAdd("master_"+b.ID, id)
+				bm.Store.SAdd("master_"+b.ID, id)
 			}
 		}
 	}
@@ -168,93 +277,197 @@ func (bm *BotMaid) startBot() {
 			for u := range updates {
 				up := u
 				go func(u *Update) {
-					if u.Message == nil || !u.Time.After(bm.respTime) {
-						return
-					}
-
 					u.Bot = b
+					bm.Dispatch(u)
+				}(up)
+			}
+		}(bot)
+	}
+}
 
-					u.Message.Flags = map[string]*pflag.FlagSet{}
+// Dispatch runs an update through the middlewares and then the routes matching it until one of them handles it. The Bot of the update must be set.
+func (bm *BotMaid) Dispatch(u *Update) {
+	if !u.Time.After(bm.respTime) {
+		return
+	}
 
-					if (*b.API).Platform() == "Telegram" {
-						if u.User != nil && u.User.UserName != "" {
-							bm.Redis.HSet("telegramUsers", fmt.Sprintf("%v", u.User.UserName), u.User.ID)
-						}
+	h := bm.route
+	for i := len(bm.Middlewares) - 1; i >= 0; i-- {
+		h = bm.Middlewares[i](h)
+	}
+	h(u)
+}
 
-						u.Message.Content = strings.ReplaceAll(u.Message.Content, "—", "--")
-					}
+func (bm *BotMaid) route(u *Update) bool {
+	for _, r := range bm.Routes {
+		if r.matches(u) && r.Do(u) {
+			return true
+		}
+	}
 
-					if bm.Conf.Log {
-						logText := u.Message.Content
-						if u.User != nil {
-							logText = u.User.NickName + ": " + logText
-						}
-						if u.Chat != nil && u.Chat.Title != "" {
-							logText = "[" + u.Chat.Title + "]" + logText
-						}
-						log.Println(logText)
-					}
+	return false
+}
 
-					args, err := shlex.Split(u.Message.Content)
-					u.Message.Args = args
-					u.Message.Command = bm.extractCommand(u)
-					if err != nil && u.Message.Command != "" {
-						bm.Reply(u, fmt.Sprintf(bm.Words["invalidParameters"], bm.At(u.User), u.Message.Content))
-						return
-					}
+// dispatchCommands runs an update through the command extraction, the flag parsing and the commands.
+func (bm *BotMaid) dispatchCommands(u *Update) bool {
+	if u.Callback != nil {
+		return false
+	}
 
-					for _, c := range bm.Commands {
-						if c.Help != nil && c.Help.Menu != "" {
-							if c.Help.SetFlag == nil {
-								c.Help.SetFlag = func(flag *pflag.FlagSet) {}
-							}
+	if u.Message == nil {
+		return bm.dispatchEvent(u)
+	}
 
-							u.Message.Flags[c.Help.Menu] = pflag.NewFlagSet(c.Help.Menu, pflag.ContinueOnError)
-							u.Message.Flags[c.Help.Menu].SortFlags = true
-							c.Help.SetFlag(u.Message.Flags[c.Help.Menu])
+	u.Message.Flags = map[string]*pflag.FlagSet{}
 
-							u.Message.Flags[c.Help.Menu].Parse(u.Message.Args)
-						}
-					}
+	args, err := shlex.Split(u.Message.Content)
+	u.Message.Args = args
+	u.Message.Command = bm.extractCommand(u)
+	if err != nil && u.Message.Command != "" && u.User != nil {
+		bm.Reply(u, fmt.Sprintf(bm.Words["invalidParameters"], bm.At(u.User), u.Message.Content))
+		return true
+	}
 
-					for _, c := range bm.Commands {
-						if c.Help != nil && len(c.Help.Names) != 0 && !Contains(c.Help.Names, u.Message.Command) {
-							continue
-						}
-
-						if c.Help == nil || c.Help.Menu == "" {
-							if c.Do(u, nil) {
-								break
-							}
-							continue
-						}
-
-						if c.Do(u, u.Message.Flags[c.Help.Menu]) {
-							break
-						}
-					}
-				}(up)
+	for _, c := range bm.Commands {
+		if c.Help != nil && c.Help.Menu != "" {
+			if c.Help.SetFlag == nil {
+				c.Help.SetFlag = func(flag *pflag.FlagSet) {}
 			}
-		}(bot)
+
+			u.Message.Flags[c.Help.Menu] = pflag.NewFlagSet(c.Help.Menu, pflag.ContinueOnError)
+			u.Message.Flags[c.Help.Menu].SortFlags = true
+			c.Help.SetFlag(u.Message.Flags[c.Help.Menu])
+
+			u.Message.Flags[c.Help.Menu].Parse(u.Message.Args)
+		}
 	}
+
+	for _, c := range bm.Commands {
+		if !c.handles(u.Type) {
+			continue
+		}
+
+		if c.Help != nil && len(c.Help.Names) != 0 && !Contains(c.Help.Names, u.Message.Command) {
+			continue
+		}
+
+		if len(c.Triggers) != 0 && !bm.matchTriggers(u, c) {
+			continue
+		}
+
+		if !bm.commandEnabled(u, c) {
+			continue
+		}
+
+		if !bm.HasRole(u, c.Permission) {
+			if c.Help == nil || len(c.Help.Names) == 0 || u.User == nil {
+				continue
+			}
+
+			bm.Reply(u, fmt.Sprintf(bm.Words["noPermission"], bm.At(u.User), u.Message.Command))
+			return true
+		}
+
+		if c.Help == nil || c.Help.Menu == "" {
+			if bm.waitCooldown(u, c) {
+				return true
+			}
+			if c.Do(u, nil) {
+				return true
+			}
+			continue
+		}
+
+		if sub, f, name := bm.subcommand(u, c); sub != nil {
+			if !bm.HasRole(u, sub.Permission) {
+				if u.User != nil {
+					bm.Reply(u, fmt.Sprintf(bm.Words["noPermission"], bm.At(u.User), name))
+				}
+				return true
+			}
+
+			if len(sub.Args) != 0 {
+				if err := bm.parseArgs(u, sub.Args, f); err != nil {
+					bm.replyInvalidArgs(u, name, sub)
+					return true
+				}
+			}
+
+			if bm.waitCooldown(u, c) {
+				return true
+			}
+			if sub.Do == nil || !sub.Do(u, f) {
+				bm.pushHelp(u, name, false)
+			}
+			return true
+		}
+
+		if len(c.Args) != 0 {
+			if err := bm.parseArgs(u, c.Args, u.Message.Flags[c.Help.Menu]); err != nil {
+				if len(c.Help.Names) == 0 {
+					continue
+				}
+
+				bm.replyInvalidArgs(u, u.Message.Command, c)
+				return true
+			}
+		}
+
+		if bm.waitCooldown(u, c) {
+			return true
+		}
+		if c.Do(u, u.Message.Flags[c.Help.Menu]) {
+			return true
+		}
+	}
+
+	if u.Message.Command != "" && u.User != nil && bm.findCommand(u.Message.Command) == nil {
+		return bm.replyUndefCommand(u, u.Message.Command, false)
+	}
+
+	return false
 }
 
-// New creates a BotMaid.
+// New creates a BotMaid with a config file.
 func New(configFile string) (*BotMaid, error) {
+	conf, err := toml.LoadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("Init botmaid: Read config: %v", err)
+	}
+
+	return NewWithConfig(conf)
+}
+
+// NewWithConfig creates a BotMaid with a parsed config.
+func NewWithConfig(conf *toml.Tree) (*BotMaid, error) {
 	bm := &BotMaid{
 		Bots: map[string]*Bot{},
+
+		SessionHandlers: map[string]*SessionHandler{},
+
 		Conf: &botMaidConfig{
 			Log: true,
 		},
 
 		respTime: time.Now(),
 		history:  map[int64][]time.Time{},
+		webhooks: map[string]string{},
 	}
 
-	conf, err := toml.LoadFile(configFile)
-	if err != nil {
-		return nil, fmt.Errorf("Init botmaid: Read config: %v", err)
-	}
+	bm.Use(bm.CacheTelegramUsersMiddleware)
+	bm.Use(bm.ReplaceEmDashMiddleware)
+	bm.Use(bm.LogMiddleware)
+	bm.Use(bm.ChatAdminMiddleware)
+	bm.Use(bm.BanMiddleware)
+	bm.Use(bm.SessionMiddleware)
+
+	bm.Handle(&Route{
+		Types: []string{UpdateCallbackQuery},
+		Do:    bm.dispatchCallback,
+	})
+	bm.Handle(&Route{
+		Do: bm.dispatchCommands,
+	})
 
 	if f, ok := conf.Get("Log.Log").(bool); ok {
 		bm.Conf.Log = f
@@ -270,6 +483,35 @@ func New(configFile string) (*BotMaid, error) {
 		bm.Conf.CommandPrefix = []string{"/"}
 	}
 
+	bm.Conf.SuggestionDistance = 2
+	if a, ok := conf.Get("Command.SuggestionDistance").(int64); ok {
+		bm.Conf.SuggestionDistance = int(a)
+	}
+
+	bm.Conf.Permission.ChatAdminTTL = time.Minute * 10
+	if a, ok := conf.Get("Permission.ChatAdminTTL").(int64); ok {
+		bm.Conf.Permission.ChatAdminTTL = time.Duration(a) * time.Second
+	}
+
+	bm.Conf.Ban.ReplyLoop = time.Minute * 10
+	if a, ok := conf.Get("Ban.ReplyLoop").(int64); ok {
+		bm.Conf.Ban.ReplyLoop = time.Duration(a) * time.Second
+	}
+
+	bm.Conf.Session.Timeout = time.Minute * 5
+	if a, ok := conf.Get("Session.Timeout").(int64); ok {
+		bm.Conf.Session.Timeout = time.Duration(a) * time.Second
+	}
+	if ss, ok := conf.Get("Session.Cancel").([]interface{}); ok {
+		for _, v := range ss {
+			if s, ok := v.(string); ok {
+				bm.Conf.Session.Cancel = append(bm.Conf.Session.Cancel, s)
+			}
+		}
+	} else {
+		bm.Conf.Session.Cancel = []string{"cancel"}
+	}
+
 	if conf.Has("Redis") {
 		bm.Conf.Redis.Address = "127.0.0.1"
 		if s, ok := conf.Get("Redis.Address").(string); ok {
@@ -283,12 +525,39 @@ func New(configFile string) (*BotMaid, error) {
 		}
 	}
 
-	if bm.Conf.Redis.Address != "" {
-		bm.Redis = redis.NewClient(&redis.Options{
-			Addr:     bm.Conf.Redis.Address,
-			Password: bm.Conf.Redis.Password,
-			DB:       bm.Conf.Redis.Database,
-		})
+	if s, ok := conf.Get("Store.Type").(string); ok {
+		bm.Conf.Store.Type = s
+	} else if bm.Conf.Redis.Address != "" {
+		bm.Conf.Store.Type = "Redis"
+	} else {
+		bm.Conf.Store.Type = "Memory"
+	}
+	bm.Conf.Store.Path = "botmaid.json"
+	if s, ok := conf.Get("Store.Path").(string); ok {
+		bm.Conf.Store.Path = s
+	}
+
+	if bm.Conf.Store.Type == "Redis" {
+		if bm.Conf.Redis.Address == "" {
+			bm.Conf.Redis.Address = "127.0.0.1"
+		}
+		bm.Store = &RedisStore{
+			Client: redis.NewClient(&redis.Options{
+				Addr:     bm.Conf.Redis.Address,
+				Password: bm.Conf.Redis.Password,
+				DB:       bm.Conf.Redis.Database,
+			}),
+		}
+	} else if bm.Conf.Store.Type == "File" {
+		s, err := NewFileStore(bm.Conf.Store.Path)
+		if err != nil {
+			return nil, fmt.Errorf("Init botmaid: %v", err)
+		}
+		bm.Store = s
+	} else if bm.Conf.Store.Type == "Memory" {
+		bm.Store = NewMemoryStore()
+	} else {
+		return nil, fmt.Errorf("Init botmaid: Unknown type of store %v", bm.Conf.Store.Type)
 	}
 
 	for _, v := range conf.Keys() {
@@ -334,6 +603,7 @@ Use "help [COMMAND] for more information about a command."`, bm.Conf.CommandPref
 		"versetVerHelp":       "appoint the version to manage",
 		"versetLogHelp":       "add a sentence to the change log",
 		"versetBroadcastHelp": "broadcast the change log",
+		"versetSetHelp":       "set the current version",
 		"upgraded":            "New version! ",
 		"subscribed":          "\"%v\" has been subscibed on this Chat.",
 		"unsubscribed":        "\"%v\" has been unsubscibed on this Chat.",
@@ -341,6 +611,46 @@ Use "help [COMMAND] for more information about a command."`, bm.Conf.CommandPref
 		"subEntriesFormat":    "\"%v\"",
 		"subEntriesSeparator": ", ",
 		"subEntriesAnd":       " and ",
+		"friendRequest":       "friend request",
+		"groupRequest":        "group joining request",
+		"groupInvitation":     "group invitation",
+		"requestQueued":       "[%v] A %v from %v is waiting for review (ID: %v): %v",
+		"requestList":         "Pending requests:%v",
+		"noRequest":           "There is no pending request.",
+		"requestApproved":     "The request %v has been approved.",
+		"requestRejected":     "The request %v has been rejected.",
+		"requestNotFound":     "%v, the request \"%v\" does not exist.",
+		"banned":              "%v has been banned%v.",
+		"unbanned":            "%v has been unbanned.",
+		"notBanned":           "%v has not been banned.",
+		"banUntil":            " until %v",
+		"banList":             "Banned:%v",
+		"noBan":               "Nothing has been banned.",
+		"banChat":             "Chat",
+		"banUser":             "User",
+		"invalidBanTarget":    "%v, \"%v\" is not a valid chat ID or user.",
+		"banUserHelp":         "ban a user instead of a chat",
+		"banTimeHelp":         "the duration of the ban, such as 1h30m, forever if not set",
+		"replyLoop":           "reply loop",
+		"roleGranted":         "The role %v has been granted to %v.",
+		"roleRevoked":         "The role %v has been revoked from %v.",
+		"roleList":            "The roles of %v: %v",
+		"noRole":              "%v has no role.",
+		"roleChatHelp":        "grant or revoke the role in this chat only",
+		"commandEnabled":      "%v has been enabled in the %v scope.",
+		"commandDisabled":     "%v has been disabled in the %v scope.",
+		"cannotDisable":       "%v, the command \"%v\" cannot be disabled.",
+		"invalidScope":        "%v, the scope \"%v\" is invalid, it should be chat, bot or global.",
+		"commandList":         "Commands:%v",
+		"commandOn":           "enabled",
+		"commandOff":          "disabled",
+		"scopeHelp":           "the scope, which is chat, bot or global",
+		"sessionCanceled":     "%v, the conversation has been canceled.",
+		"invalidAnswer":       "%v, %v, please answer again.",
+		"didYouMean":          "Did you mean %v?",
+		"subcommands":         "Subcommands:",
+		"pleaseWait":          "%v, please wait %v before using \"%v\" again.",
+		"pleaseWaitTrigger":   "%v, please wait %v.",
 	}
 
 	return bm, nil
@@ -348,9 +658,9 @@ Use "help [COMMAND] for more information about a command."`, bm.Conf.CommandPref
 
 // Start starts the BotMaid.
 func (bm *BotMaid) Start() error {
-	err := bm.Redis.Ping().Err()
+	err := bm.Store.Ping()
 	if err != nil {
-		return fmt.Errorf("Init botmaid: Connect Redis: %v", err)
+		return fmt.Errorf("Init botmaid: Connect store: %v", err)
 	}
 
 	sort.Stable(CommandSlice(bm.Commands))
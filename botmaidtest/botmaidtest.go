@@ -0,0 +1,284 @@
+// Package botmaidtest includes a fake API and a harness to test commands without any network.
+package botmaidtest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"github.com/the-cattail/botmaid"
+)
+
+// API is a scriptable fake API recording all updates pushed by the bot.
+//
+// PlatformName overrides the platform returned by Platform if it is not empty.
+// PushFunc, if not nil, is called after an update is recorded and its results are returned by Push.
+// ChatAdmins is the IDs of users who are admins of all chats.
+type API struct {
+	*botmaid.APIConsole
+
+	PlatformName string
+	PushFunc     func(*botmaid.Update) (*botmaid.Update, error)
+	ChatAdmins   []int64
+
+	Updates botmaid.UpdateChannel
+
+	mu       sync.Mutex
+	lastID   int64
+	pushed   []*botmaid.Update
+	edited   []*botmaid.Update
+	deleted  []*botmaid.Update
+	answered []string
+	approved map[string]bool
+}
+
+// NewAPI creates an API whose messages are sent by the user userID in the private chat with the user.
+func NewAPI(userID int64, userName string) *API {
+	return &API{
+		APIConsole: &botmaid.APIConsole{
+			UserID:   userID,
+			UserName: userName,
+			ChatID:   userID,
+			ChatType: "private",
+		},
+		Updates: make(botmaid.UpdateChannel),
+	}
+}
+
+// Pull returns the channel Updates, so that updates sent into it are handled by a started BotMaid.
+func (a *API) Pull(pc *botmaid.PullConfig) (botmaid.UpdateChannel, botmaid.ErrorChannel) {
+	return a.Updates, make(botmaid.ErrorChannel)
+}
+
+// Push records an update and returns it back with a new ID.
+func (a *API) Push(update *botmaid.Update) (*botmaid.Update, error) {
+	a.mu.Lock()
+	if update.Type == "Delete" {
+		a.deleted = append(a.deleted, update)
+	} else {
+		a.lastID++
+		update.ID = a.lastID
+		a.pushed = append(a.pushed, update)
+	}
+	a.mu.Unlock()
+
+	if a.PushFunc != nil {
+		return a.PushFunc(update)
+	}
+
+	if update.Type == "Delete" {
+		return nil, nil
+	}
+	return update, nil
+}
+
+// Edit records an edited update and returns it back.
+func (a *API) Edit(update *botmaid.Update) (*botmaid.Update, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.edited = append(a.edited, update)
+	return update, nil
+}
+
+// Approve records the result of a request.
+func (a *API) Approve(u *botmaid.Update, approve bool, reason string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.approved == nil {
+		a.approved = map[string]bool{}
+	}
+	a.approved[u.Request.ID] = approve
+	return nil
+}
+
+// Approved returns the results of requests by their IDs.
+func (a *API) Approved() map[string]bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m := map[string]bool{}
+	for k, v := range a.approved {
+		m[k] = v
+	}
+	return m
+}
+
+// IsChatAdmin checks if the User of an update is in ChatAdmins.
+func (a *API) IsChatAdmin(u *botmaid.Update) (bool, error) {
+	for _, v := range a.ChatAdmins {
+		if v == u.User.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AnswerCallback records the answer of a callback query.
+func (a *API) AnswerCallback(u *botmaid.Update, text string, alert bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.answered = append(a.answered, text)
+	return nil
+}
+
+// Platform returns a string showing the platform of the bot.
+func (a *API) Platform() string {
+	if a.PlatformName != "" {
+		return a.PlatformName
+	}
+	return "Test"
+}
+
+// Pushed returns all updates pushed except deletions.
+func (a *API) Pushed() []*botmaid.Update {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]*botmaid.Update{}, a.pushed...)
+}
+
+// Edited returns all updates edited.
+func (a *API) Edited() []*botmaid.Update {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]*botmaid.Update{}, a.edited...)
+}
+
+// Deleted returns all updates deleted.
+func (a *API) Deleted() []*botmaid.Update {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]*botmaid.Update{}, a.deleted...)
+}
+
+// Answered returns the texts of all answers of callback queries.
+func (a *API) Answered() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]string{}, a.answered...)
+}
+
+// Reset clears all recorded updates, answers and results of requests.
+func (a *API) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pushed = nil
+	a.edited = nil
+	a.deleted = nil
+	a.answered = nil
+	a.approved = nil
+}
+
+// Harness includes a BotMaid with a MemoryStore and a bot using the fake API.
+type Harness struct {
+	BotMaid *botmaid.BotMaid
+	Bot     *botmaid.Bot
+	API     *API
+	Store   *botmaid.MemoryStore
+
+	mu     sync.Mutex
+	lastID int64
+}
+
+// New creates a Harness, messages are sent by the user 1 named "user" by default.
+func New() (*Harness, error) {
+	conf, err := toml.Load("[Store]\nType = \"Memory\"\n[Log]\nLog = false\n")
+	if err != nil {
+		return nil, fmt.Errorf("Init harness: %v", err)
+	}
+
+	bm, err := botmaid.NewWithConfig(conf)
+	if err != nil {
+		return nil, fmt.Errorf("Init harness: %v", err)
+	}
+
+	h := &Harness{
+		BotMaid: bm,
+		API:     NewAPI(1, "user"),
+		Store:   bm.Store.(*botmaid.MemoryStore),
+	}
+
+	h.Bot = bm.AddBot("Bot_Test", h.API, &botmaid.User{
+		ID:       0,
+		UserName: "botmaid",
+		NickName: "BotMaid",
+	})
+
+	return h, nil
+}
+
+// NewMessage creates a message update sent by the default user in the private chat, fields of it could be changed before sending.
+func (h *Harness) NewMessage(content string) *botmaid.Update {
+	h.mu.Lock()
+	h.lastID++
+	id := h.lastID
+	h.mu.Unlock()
+
+	u := &botmaid.Update{
+		ID:   id,
+		Type: botmaid.UpdateMessage,
+		Time: time.Now(),
+
+		Chat: &botmaid.Chat{
+			ID:   h.API.ChatID,
+			Type: h.API.ChatType,
+		},
+		User: &botmaid.User{
+			ID:       h.API.UserID,
+			UserName: h.API.UserName,
+			NickName: h.API.UserName,
+		},
+		Message: &botmaid.Message{
+			ID:      id,
+			Content: content,
+		},
+	}
+
+	u.Chat.Update = u
+	u.User.Update = u
+	u.Message.Update = u
+
+	return u
+}
+
+// NewCallback creates a callback query update with the data sent by the default user in the private chat.
+func (h *Harness) NewCallback(data string) *botmaid.Update {
+	u := h.NewMessage("")
+	u.Type = botmaid.UpdateCallbackQuery
+	u.Callback = &botmaid.Callback{
+		ID:     fmt.Sprint(u.ID),
+		Data:   data,
+		Update: u,
+	}
+	return u
+}
+
+// Send dispatches an update synchronously and returns the updates pushed meanwhile.
+func (h *Harness) Send(u *botmaid.Update) []*botmaid.Update {
+	sort.Stable(h.BotMaid.Commands)
+
+	before := len(h.API.Pushed())
+
+	u.Bot = h.Bot
+	h.BotMaid.Dispatch(u)
+
+	return h.API.Pushed()[before:]
+}
+
+// Say sends a message with the content by the default user and returns the replies in plain text.
+func (h *Harness) Say(content string) []string {
+	ss := []string{}
+	for _, u := range h.Send(h.NewMessage(content)) {
+		ss = append(ss, u.Message.PlainText())
+	}
+	return ss
+}
@@ -0,0 +1,61 @@
+package botmaidtest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestHarness(t *testing.T) {
+	h, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, strings.Join(f.Args()[1:], " "))
+			return true
+		},
+		Help: &botmaid.Help{
+			Menu:  "echo",
+			Names: []string{"echo"},
+		},
+	})
+	h.BotMaid.AddCallback("cb", func(u *botmaid.Update) bool {
+		h.BotMaid.AnswerCallback(u, u.Callback.Data, false)
+		return true
+	})
+
+	if got := h.Say("/echo a b"); !reflect.DeepEqual(got, []string{"a b"}) {
+		t.Errorf("/echo a b: replies %q, want %q", got, []string{"a b"})
+	}
+	if got := h.Say("hello"); len(got) != 0 {
+		t.Errorf("hello: replies %q, want none", got)
+	}
+
+	u := h.NewMessage("/echo c")
+	if u.Bot != nil || u.User.ID != 1 || u.Chat.ID != 1 {
+		t.Errorf("NewMessage: bot %v, user %v, chat %v", u.Bot, u.User.ID, u.Chat.ID)
+	}
+	us := h.Send(u)
+	if u.Bot != h.Bot || len(us) != 1 || us[0].Chat != u.Chat {
+		t.Errorf("Send: bot %v, pushed %v", u.Bot, us)
+	}
+	if n := len(h.API.Pushed()); n != 2 {
+		t.Errorf("Pushed: %v updates, want 2", n)
+	}
+
+	h.Send(h.NewCallback("cb_1"))
+	if got := h.API.Answered(); !reflect.DeepEqual(got, []string{"cb_1"}) {
+		t.Errorf("Answered: %q, want %q", got, []string{"cb_1"})
+	}
+
+	h.API.Reset()
+	if len(h.API.Pushed()) != 0 || len(h.API.Answered()) != 0 {
+		t.Error("Reset: records are not cleared")
+	}
+}
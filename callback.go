@@ -0,0 +1,53 @@
+package botmaid
+
+import (
+	"strings"
+)
+
+// CallbackHandler is a func handling the callback queries whose data begin with Prefix.
+type CallbackHandler struct {
+	Prefix string
+
+	Do func(*Update) bool
+}
+
+// AddCallback adds a callback handler, handlers are tried in the order of adding until one of them returns true.
+func (bm *BotMaid) AddCallback(prefix string, do func(*Update) bool) {
+	bm.Callbacks = append(bm.Callbacks, &CallbackHandler{
+		Prefix: prefix,
+		Do:     do,
+	})
+}
+
+// AnswerCallback answers the callback query of an update with a notification or an alert.
+func (bm *BotMaid) AnswerCallback(u *Update, text string, alert bool) error {
+	return (*u.Bot.API).AnswerCallback(u, text, alert)
+}
+
+// ReplyKeyboard replies a message back with a keyboard.
+func (bm *BotMaid) ReplyKeyboard(u *Update, s string, kb [][]Button) (*Update, error) {
+	bm.antiReplyLoop(u)
+
+	return (*u.Bot.API).Push(&Update{
+		Message: &Message{
+			Content:  s,
+			Keyboard: kb,
+		},
+		Chat: u.Chat,
+		Bot:  u.Bot,
+	})
+}
+
+func (bm *BotMaid) dispatchCallback(u *Update) bool {
+	for _, c := range bm.Callbacks {
+		if !strings.HasPrefix(u.Callback.Data, c.Prefix) {
+			continue
+		}
+
+		if c.Do(u) {
+			return true
+		}
+	}
+
+	return false
+}
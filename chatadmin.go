@@ -0,0 +1,47 @@
+package botmaid
+
+import (
+	"fmt"
+	"log"
+)
+
+func chatAdminKey(u *Update, userID int64) string {
+	return fmt.Sprintf("chatAdmin_%v_%v_%v", u.Bot.ID, u.Chat.ID, userID)
+}
+
+// IsChatAdmin checks if the user of an update is an owner or admin of the chat on the platform. The result is cached in the Store for Permission.ChatAdminTTL.
+func (bm *BotMaid) IsChatAdmin(u *Update) bool {
+	if u.User == nil || u.Chat == nil {
+		return false
+	}
+
+	key := chatAdminKey(u, u.User.ID)
+	if v, _ := bm.Store.Get(key); v != "" {
+		return v == "1"
+	}
+
+	is, err := (*u.Bot.API).IsChatAdmin(u)
+	if err != nil {
+		if bm.Conf.Log {
+			log.Printf("Check chat admin: %v\n", err)
+		}
+		return false
+	}
+
+	v := "0"
+	if is {
+		v = "1"
+	}
+	bm.Store.Set(key, v, bm.Conf.Permission.ChatAdminTTL)
+	return is
+}
+
+// ChatAdminMiddleware drops the cached admin status of a user once it changes on the platform.
+func (bm *BotMaid) ChatAdminMiddleware(next Handler) Handler {
+	return func(u *Update) bool {
+		if u.Chat != nil && u.User != nil && (u.Type == UpdateAdminChanged || u.Type == UpdateMemberLeft || u.Type == UpdateMemberKicked) {
+			bm.Store.Del(chatAdminKey(u, u.User.ID))
+		}
+		return next(u)
+	}
+}
@@ -0,0 +1,31 @@
+package botmaid_test
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestChatAdmin(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, "done")
+			return true
+		},
+		Permission: botmaid.RoleChatAdmin,
+		Help: &botmaid.Help{
+			Menu:  "kick",
+			Names: []string{"kick"},
+		},
+	})
+
+	expectReplies(t, "/kick", h.Say("/kick"), `@user, you don't have permission to use "kick"`)
+
+	h.API.ChatAdmins = []int64{2}
+	expectReplies(t, "/kick by a chat admin", sayAs(h, 2, "/kick"), "done")
+
+	h.API.ChatAdmins = nil
+	expectReplies(t, "/kick by a cached chat admin", sayAs(h, 2, "/kick"), "done")
+}
@@ -1,18 +1,96 @@
 package botmaid
 
 import (
+	"log"
+	"regexp"
 	"strings"
 
 	"github.com/spf13/pflag"
 )
 
 // Command is a func with priority value so that we can sort some Commands to make them in a specific order.
+//
+// Events are the types of updates handled by the command, it handles only new messages if Events is empty.
+// Permission is the role required to use the command, the dispatcher refuses users without it.
+// AlwaysEnabled commands could not be disabled, the commands enabling commands should set it so that disabling could always be undone.
+// Cooldowns limit the uses of the command matched by its names or triggers, the dispatcher asks users to wait once any of them is exceeded. Uses are counted after the arguments are validated.
+//
+// Triggers declare the messages handled by the command without a command name, the command handles only messages matching any of them if it is not empty.
+// Args declares the positional arguments of the command, the dispatcher replies invalidParameters with the usage if they are invalid.
+//
+// Subcommands are named by the arguments following the name of the command, such as "verset log add". The deepest subcommand named handles the message with its own flags instead of the command, Names of its Help are its names. Priority, Events and Cooldowns of subcommands are ignored.
 type Command struct {
 	Do func(*Update, *pflag.FlagSet) bool
 
 	Priority int
 
-	Help *Help
+	Events        []string
+	Permission    string
+	AlwaysEnabled bool
+	Cooldowns     []Cooldown
+
+	Triggers []Trigger
+	Args     []Arg
+	Help     *Help
+
+	Subcommands []*Command
+}
+
+func (c *Command) handles(t string) bool {
+	if len(c.Events) == 0 {
+		return t == UpdateMessage
+	}
+	return Contains(c.Events, t)
+}
+
+func (c *Command) findSubcommand(name string) *Command {
+	for _, sub := range c.Subcommands {
+		if sub.Help != nil && Contains(sub.Help.Names, name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// subcommand returns the deepest subcommand of a command named by the arguments of a message with its full name, or nil if there is none. The flags of the subcommand are parsed from the arguments following its name.
+func (bm *BotMaid) subcommand(u *Update, c *Command) (*Command, *pflag.FlagSet, string) {
+	if len(c.Subcommands) == 0 || len(u.Message.Args) < 2 {
+		return nil, nil, ""
+	}
+
+	var sub *Command
+	name := u.Message.Command
+	args := u.Message.Args[1:]
+
+	for cur := c; len(args) > 0; {
+		next := cur.findSubcommand(args[0])
+		if next == nil {
+			break
+		}
+
+		sub, cur = next, next
+		name += " " + args[0]
+		args = args[1:]
+	}
+
+	if sub == nil {
+		return nil, nil, ""
+	}
+
+	f := sub.Help.flagSet(name)
+	f.Parse(append([]string{name}, args...))
+	u.Message.Flags[name] = f
+	return sub, f, name
+}
+
+// findCommand returns the command with a name, or nil if there is none.
+func (bm *BotMaid) findCommand(name string) *Command {
+	for _, c := range bm.Commands {
+		if c.Help != nil && Contains(c.Help.Names, name) {
+			return c
+		}
+	}
+	return nil
 }
 
 // CommandSlice is a slice of Command that could be sort.
@@ -41,9 +119,57 @@ func (bm *BotMaid) AddCommand(c *Command) {
 		}
 	}
 
+	for i := range c.Triggers {
+		if c.Triggers[i].Type != TriggerRegex {
+			continue
+		}
+
+		re, err := regexp.Compile(c.Triggers[i].Pattern)
+		if err != nil {
+			if bm.Conf.Log {
+				log.Printf("Add command: Invalid trigger %v: %v\n", c.Triggers[i].Pattern, err)
+			}
+			continue
+		}
+		c.Triggers[i].regexp = re
+	}
+
 	bm.Commands = append(bm.Commands, c)
 }
 
+// On adds a handler of a type of updates as a command, such as member_joined.
+func (bm *BotMaid) On(t string, do func(*Update) bool) {
+	bm.AddCommand(&Command{
+		Do: func(u *Update, _ *pflag.FlagSet) bool {
+			return do(u)
+		},
+		Events: []string{t},
+	})
+}
+
+// dispatchEvent runs the commands handling the type of an update without message.
+func (bm *BotMaid) dispatchEvent(u *Update) bool {
+	for _, c := range bm.Commands {
+		if len(c.Events) == 0 || !Contains(c.Events, u.Type) {
+			continue
+		}
+
+		if !bm.commandEnabled(u, c) {
+			continue
+		}
+
+		if !bm.HasRole(u, c.Permission) {
+			continue
+		}
+
+		if c.Do(u, nil) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (bm *BotMaid) extractCommand(u *Update) string {
 	if len(u.Message.Args) < 1 {
 		return ""
@@ -0,0 +1,76 @@
+package botmaid_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestSubcommands(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.Conf.Ban.ReplyLoop = 0
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			return false
+		},
+		Help: &botmaid.Help{
+			Menu:  "tool",
+			Names: []string{"tool"},
+		},
+		Subcommands: []*botmaid.Command{
+			{
+				Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+					return false
+				},
+				Help: &botmaid.Help{
+					Names: []string{"list", "ls"},
+				},
+				Subcommands: []*botmaid.Command{
+					{
+						Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+							all, _ := f.GetBool("all")
+							h.BotMaid.Reply(u, strings.Join(f.Args(), " "))
+							if all {
+								h.BotMaid.Reply(u, "all")
+							}
+							return true
+						},
+						Help: &botmaid.Help{
+							Names: []string{"all"},
+							Help:  "List all tools.",
+							SetFlag: func(f *pflag.FlagSet) {
+								f.BoolP("all", "a", false, "")
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			return false
+		},
+		Help: &botmaid.Help{
+			Menu: "any",
+		},
+		Subcommands: []*botmaid.Command{
+			{
+				Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+					h.BotMaid.Reply(u, "sub")
+					return true
+				},
+				Help: &botmaid.Help{
+					Names: []string{"sub"},
+				},
+			},
+		},
+	})
+
+	expectReplies(t, "", h.Say(""))
+	expectReplies(t, "x sub", h.Say("x sub"), "sub")
+	expectReplies(t, "/tool ls all -a x", h.Say("/tool ls all -a x"), "tool ls all x", "all")
+	expectReplies(t, "/tool list", h.Say("/tool list"), "Subcommands:\n  all  List all tools.")
+}
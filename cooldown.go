@@ -0,0 +1,97 @@
+package botmaid
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScopeUser is the scope of a cooldown counting uses of each user.
+const (
+	ScopeUser = "user"
+)
+
+// Cooldown limits a command to Uses uses per Window in a scope, which is ScopeUser, ScopeChat or ScopeGlobal.
+type Cooldown struct {
+	Scope  string
+	Uses   int
+	Window time.Duration
+}
+
+func (cd *Cooldown) key(u *Update, name string) string {
+	switch cd.Scope {
+	case ScopeUser:
+		return fmt.Sprintf("cooldown_%v_%v_user_%v", u.Bot.ID, name, u.User.ID)
+	case ScopeChat:
+		return fmt.Sprintf("cooldown_%v_%v_chat_%v", u.Bot.ID, name, u.Chat.ID)
+	}
+	return fmt.Sprintf("cooldown_%v_%v", u.Bot.ID, name)
+}
+
+// cooldownName returns the name counting the uses of a command, which is its menu, its first name or its first trigger, or an empty string if the command is matched by neither names nor triggers.
+func (c *Command) cooldownName() string {
+	if c.Help != nil && len(c.Help.Names) != 0 {
+		if c.Help.Menu != "" {
+			return c.Help.Menu
+		}
+		return c.Help.Names[0]
+	}
+	if len(c.Triggers) != 0 {
+		return c.Triggers[0].Type + ":" + c.Triggers[0].Pattern
+	}
+	return ""
+}
+
+// cooldown counts a use of a command and returns the time to wait if any cooldown of the command is exceeded, and whether it is the first use exceeding the cooldown in its window.
+func (bm *BotMaid) cooldown(u *Update, c *Command, name string) (time.Duration, bool) {
+	for i := range c.Cooldowns {
+		cd := &c.Cooldowns[i]
+		if (cd.Scope == ScopeUser && u.User == nil) || (cd.Scope == ScopeChat && u.Chat == nil) {
+			continue
+		}
+
+		key := cd.key(u, name)
+		n, err := bm.Store.Incr(key)
+		if err != nil {
+			continue
+		}
+		if n == 1 {
+			bm.Store.Expire(key, cd.Window)
+		}
+
+		if n > int64(cd.Uses) {
+			ttl, _ := bm.Store.TTL(key)
+			if ttl <= 0 {
+				bm.Store.Expire(key, cd.Window)
+				ttl = cd.Window
+			}
+			return ttl, n == int64(cd.Uses)+1
+		}
+	}
+
+	return 0, false
+}
+
+// waitCooldown counts a use of a command handling an update and returns true if any cooldown of the command is exceeded, so that the command should not run. It replies pleaseWait only for the first exceeding use in a window, so that spamming a command doesn't make the bot spam back.
+//
+// Commands matched by neither names nor triggers are not limited, since they might not handle the update.
+func (bm *BotMaid) waitCooldown(u *Update, c *Command) bool {
+	name := c.cooldownName()
+	if len(c.Cooldowns) == 0 || name == "" {
+		return false
+	}
+
+	d, first := bm.cooldown(u, c, name)
+	if d <= 0 {
+		return false
+	}
+
+	if first && u.User != nil {
+		d = (d + time.Second - 1).Truncate(time.Second)
+		if u.Message.Command != "" {
+			bm.Reply(u, fmt.Sprintf(bm.Words["pleaseWait"], bm.At(u.User), d, u.Message.Command))
+		} else {
+			bm.Reply(u, fmt.Sprintf(bm.Words["pleaseWaitTrigger"], bm.At(u.User), d))
+		}
+	}
+	return true
+}
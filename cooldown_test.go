@@ -0,0 +1,50 @@
+package botmaid_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestCooldown(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			return false
+		},
+		Cooldowns: []botmaid.Cooldown{
+			{Scope: botmaid.ScopeUser, Uses: 1, Window: time.Minute},
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, fmt.Sprint(u.Message.IntParam("N")))
+			return true
+		},
+		Cooldowns: []botmaid.Cooldown{
+			{Scope: botmaid.ScopeUser, Uses: 1, Window: time.Minute},
+		},
+		Args: []botmaid.Arg{
+			{Name: "N", Type: botmaid.ArgInt},
+		},
+		Help: &botmaid.Help{
+			Menu:  "roll",
+			Names: []string{"roll"},
+		},
+	})
+
+	h.Say("hello")
+	h.Say("/roll x")
+	expectReplies(t, "/roll 3", h.Say("/roll 3"), "3")
+	expectReplies(t, "/roll 4", h.Say("/roll 4"), `@user, please wait 1m0s before using "roll" again.`)
+	for i := 0; i < 10; i++ {
+		expectReplies(t, "/roll 4 again", h.Say("/roll 4"))
+	}
+	expectReplies(t, "/roll 4 by another user", sayAs(h, 2, "/roll 4"), "4")
+
+	addEcho(h)
+	expectReplies(t, "/echo after spamming", h.Say("/echo hi"), "hi")
+}
@@ -17,7 +17,21 @@ type Help struct {
 	SetFlag func(*pflag.FlagSet)
 }
 
+func (h *Help) flagSet(name string) *pflag.FlagSet {
+	f := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	f.SortFlags = true
+	if h.SetFlag != nil {
+		h.SetFlag(f)
+	}
+	return f
+}
+
 func (bm *BotMaid) pushHelp(u *Update, hc string, showUndef bool) {
+	names := strings.Fields(hc)
+	if len(names) == 0 {
+		return
+	}
+
 	for _, c := range bm.Commands {
 		if c.Help == nil {
 			continue
@@ -25,11 +39,25 @@ func (bm *BotMaid) pushHelp(u *Update, hc string, showUndef bool) {
 		if c.Help.Menu == "" {
 			continue
 		}
-		if !Contains(c.Help.Names, hc) {
+		if !Contains(c.Help.Names, names[0]) {
 			continue
 		}
 
-		lines := strings.Split(u.Message.Flags[c.Help.Menu].FlagUsages(), "\n")
+		f := u.Message.Flags[c.Help.Menu]
+		for _, v := range names[1:] {
+			c = c.findSubcommand(v)
+			if c == nil {
+				break
+			}
+		}
+		if c == nil {
+			break
+		}
+		if len(names) > 1 {
+			f = c.Help.flagSet(hc)
+		}
+
+		lines := strings.Split(f.FlagUsages(), "\n")
 		s := ""
 
 		for i := range lines {
@@ -46,7 +74,24 @@ func (bm *BotMaid) pushHelp(u *Update, hc string, showUndef bool) {
 
 			s += "\n  " + lines[i]
 		}
-		s = strings.TrimSpace(c.Help.Usage + "\n" + s + c.Help.Comment)
+		usage := c.Help.Usage
+		if usage == "" && len(c.Args) != 0 {
+			usage = argsUsage(hc, c.Args)
+		}
+		s = strings.TrimSpace(usage + "\n" + s + c.Help.Comment)
+
+		subs := []string{}
+		for _, sub := range c.Subcommands {
+			if sub.Help == nil || len(sub.Help.Names) == 0 {
+				continue
+			}
+
+			subs = append(subs, fmt.Sprintf("\n  %v  %v", sub.Help.Names[0], sub.Help.Help))
+		}
+		if len(subs) != 0 {
+			sort.Strings(subs)
+			s = strings.TrimSpace(s + "\n" + bm.Words["subcommands"] + strings.Join(subs, ""))
+		}
 
 		if s == "" {
 			bm.Reply(u, fmt.Sprintf(bm.Words["noHelpText"], bm.At(u.User), hc))
@@ -57,9 +102,7 @@ func (bm *BotMaid) pushHelp(u *Update, hc string, showUndef bool) {
 		return
 	}
 
-	if showUndef {
-		bm.Reply(u, fmt.Sprintf(bm.Words["undefCommand"], bm.At(u.User), hc))
-	}
+	bm.replyUndefCommand(u, hc, showUndef)
 }
 
 func (bm *BotMaid) HelpCommandDo(u *Update, f *pflag.FlagSet) bool {
@@ -85,8 +128,8 @@ func (bm *BotMaid) HelpCommandDo(u *Update, f *pflag.FlagSet) bool {
 		return true
 	}
 
-	if len(f.Args()) == 2 {
-		bm.pushHelp(u, f.Args()[1], true)
+	if len(f.Args()) >= 2 {
+		bm.pushHelp(u, strings.Join(f.Args()[1:], " "), true)
 		return true
 	}
 
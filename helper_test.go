@@ -0,0 +1,64 @@
+package botmaid_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+	"github.com/the-cattail/botmaid/botmaidtest"
+)
+
+func newHarness(t *testing.T) *botmaidtest.Harness {
+	h, err := botmaidtest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+// addEcho adds the command "echo" replying its arguments.
+func addEcho(h *botmaidtest.Harness) {
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, strings.Join(f.Args()[1:], " "))
+			return true
+		},
+		Help: &botmaid.Help{
+			Menu:  "echo",
+			Names: []string{"echo"},
+		},
+	})
+}
+
+func addMaster(h *botmaidtest.Harness, id int64) {
+	h.Store.SAdd("master_"+h.Bot.ID, id)
+}
+
+// sayAs sends a message by a user without a user name in the private chat with the user.
+func sayAs(h *botmaidtest.Harness, id int64, content string) []string {
+	u := h.NewMessage(content)
+	u.User.ID = id
+	u.User.UserName = ""
+	u.Chat.ID = id
+	return texts(h.Send(u))
+}
+
+func texts(us []*botmaid.Update) []string {
+	ss := []string{}
+	for _, u := range us {
+		ss = append(ss, u.Message.PlainText())
+	}
+	return ss
+}
+
+func expectReplies(t *testing.T, content string, got []string, want ...string) {
+	t.Helper()
+	if len(want) == 0 {
+		want = []string{}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%q: replies %q, want %q", content, got, want)
+	}
+}
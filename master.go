@@ -6,31 +6,42 @@ import (
 	"github.com/spf13/pflag"
 )
 
+// MasterCommandDo registers or unregisters a master, only masters could use it. The user is read from Params if the command is added with Args MasterCommandArgs, or from the first argument otherwise.
 func (bm *BotMaid) MasterCommandDo(u *Update, f *pflag.FlagSet) bool {
-	if !bm.IsMaster(u.User) {
-		bm.Reply(u, fmt.Sprintf(bm.Words["noPermission"], bm.At(u.User), "master"))
+	if !bm.requireMaster(u) {
 		return true
 	}
 
-	if len(f.Args()) != 2 {
-		return false
+	id := u.Message.IntParam("USER")
+	if _, ok := u.Message.Params["USER"]; !ok {
+		if len(f.Args()) != 2 {
+			return false
+		}
+
+		var err error
+		id, err = (*u.Bot.API).ParseUserID(u, f.Args()[1])
+		if err != nil {
+			bm.Reply(u, fmt.Sprintf(bm.Words["invalidUser"], bm.At(u.User), f.Args()[1]))
+			return true
+		}
 	}
 
-	id, err := (*u.Bot.API).ParseUserID(u, f.Args()[1])
-	if err != nil {
-		bm.Reply(u, fmt.Sprintf(bm.Words["invalidUser"], bm.At(u.User), f.Args()[1]))
-		return true
-	}
-
-	is := bm.Redis.SIsMember("master_"+u.Bot.ID, id).Val()
+	is, _ := bm.Store.SIsMember("master_"+u.Bot.ID, id)
 
 	if is {
-		bm.Redis.SRem("master_"+u.Bot.ID, id)
-		bm.Reply(u, fmt.Sprintf(bm.Words["unregMaster"], bm.At(u.User), f.Args()[1]))
+		bm.Store.SRem("master_"+u.Bot.ID, id)
+		bm.Reply(u, fmt.Sprintf(bm.Words["unregMaster"], bm.At(u.User), id))
 		return true
 	}
 
-	bm.Redis.SAdd("master_"+u.Bot.ID, id)
-	bm.Reply(u, fmt.Sprintf(bm.Words["regMaster"], bm.At(u.User), f.Args()[1]))
+	bm.Store.SAdd("master_"+u.Bot.ID, id)
+	bm.Reply(u, fmt.Sprintf(bm.Words["regMaster"], bm.At(u.User), id))
 	return true
 }
+
+// MasterCommandArgs returns the positional arguments of master.
+func (bm *BotMaid) MasterCommandArgs() []Arg {
+	return []Arg{
+		{Name: "USER", Type: ArgUser},
+	}
+}
@@ -0,0 +1,57 @@
+package botmaid
+
+import (
+	"log"
+	"strings"
+)
+
+// Handler is a func handling an update, it returns true if the update has been handled.
+type Handler func(*Update) bool
+
+// Middleware wraps the next handler, it could change the update, drop it by not calling the next handler, or observe the result of the next handler.
+type Middleware func(next Handler) Handler
+
+// Use adds a middleware, the first added one is the outermost.
+func (bm *BotMaid) Use(m Middleware) {
+	bm.Middlewares = append(bm.Middlewares, m)
+}
+
+// CacheTelegramUsersMiddleware saves the IDs of Telegram users by their user names, so that they could be mentioned by "@" and user names. It is used by default.
+func (bm *BotMaid) CacheTelegramUsersMiddleware(next Handler) Handler {
+	return func(u *Update) bool {
+		if (*u.Bot.API).Platform() == "Telegram" && u.User != nil && u.User.UserName != "" {
+			bm.Store.HSet("telegramUsers", u.User.UserName, u.User.ID)
+		}
+
+		return next(u)
+	}
+}
+
+// ReplaceEmDashMiddleware replaces the em dashes converted from "--" by Telegram clients back. It is used by default.
+func (bm *BotMaid) ReplaceEmDashMiddleware(next Handler) Handler {
+	return func(u *Update) bool {
+		if (*u.Bot.API).Platform() == "Telegram" && u.Message != nil {
+			u.Message.Content = strings.ReplaceAll(u.Message.Content, "—", "--")
+		}
+
+		return next(u)
+	}
+}
+
+// LogMiddleware logs the messages received if logging is enabled in the config. It is used by default.
+func (bm *BotMaid) LogMiddleware(next Handler) Handler {
+	return func(u *Update) bool {
+		if bm.Conf.Log && u.Message != nil {
+			logText := u.Message.Content
+			if u.User != nil {
+				logText = u.User.NickName + ": " + logText
+			}
+			if u.Chat != nil && u.Chat.Title != "" {
+				logText = "[" + u.Chat.Title + "]" + logText
+			}
+			log.Println(logText)
+		}
+
+		return next(u)
+	}
+}
@@ -0,0 +1,145 @@
+package botmaid
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+type pendingRequest struct {
+	Type     string
+	SubType  string
+	Comment  string
+	ChatID   int64
+	ChatType string
+	UserID   int64
+	NickName string
+}
+
+func (bm *BotMaid) requestKind(t, subType string) string {
+	if t == UpdateFriendRequest {
+		return bm.Words["friendRequest"]
+	}
+	if subType == "invite" {
+		return bm.Words["groupInvitation"]
+	}
+	return bm.Words["groupRequest"]
+}
+
+// Approve approves or rejects the request of an update.
+func (bm *BotMaid) Approve(u *Update, approve bool, reason string) error {
+	return (*u.Bot.API).Approve(u, approve, reason)
+}
+
+// RequestEventDo is a built-in policy of requests, it approves requests from masters and queues the others for the review of masters, who are notified by the subscription entry "request".
+func (bm *BotMaid) RequestEventDo(u *Update, f *pflag.FlagSet) bool {
+	if u.Request == nil {
+		return false
+	}
+
+	if bm.IsMaster(u.User) {
+		bm.Approve(u, true, "")
+		return true
+	}
+
+	j, err := json.Marshal(&pendingRequest{
+		Type:     u.Type,
+		SubType:  u.Request.SubType,
+		Comment:  u.Request.Comment,
+		ChatID:   u.Chat.ID,
+		ChatType: u.Chat.Type,
+		UserID:   u.User.ID,
+		NickName: u.User.NickName,
+	})
+	if err != nil {
+		return false
+	}
+	bm.Store.HSet("request_"+u.Bot.ID, u.Request.ID, string(j))
+
+	user := u.User.NickName
+	if user == "" {
+		user = fmt.Sprint(u.User.ID)
+	}
+	bm.Broadcast("request", &Message{
+		Content: fmt.Sprintf(bm.Words["requestQueued"], u.Bot.ID, bm.requestKind(u.Type, u.Request.SubType), user, u.Request.ID, u.Request.Comment),
+	})
+	return true
+}
+
+// RequestCommandDo lists, approves or rejects the pending requests, which is for masters only.
+func (bm *BotMaid) RequestCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if !bm.requireMaster(u) {
+		return true
+	}
+
+	rs, _ := bm.Store.HGetAll("request_" + u.Bot.ID)
+
+	if len(f.Args()) == 1 {
+		if len(rs) == 0 {
+			bm.Reply(u, bm.Words["noRequest"])
+			return true
+		}
+
+		lines := []string{}
+		for id, v := range rs {
+			r := &pendingRequest{}
+			if json.Unmarshal([]byte(v), r) != nil {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("\n%v: %v, %v (%v) %v", id, bm.requestKind(r.Type, r.SubType), r.NickName, r.UserID, r.Comment))
+		}
+		sort.Strings(lines)
+
+		bm.Reply(u, fmt.Sprintf(bm.Words["requestList"], strings.Join(lines, "")))
+		return true
+	}
+
+	if len(f.Args()) < 3 || (f.Args()[1] != "approve" && f.Args()[1] != "reject") {
+		return false
+	}
+
+	id := f.Args()[2]
+	r := &pendingRequest{}
+	if json.Unmarshal([]byte(rs[id]), r) != nil {
+		bm.Reply(u, fmt.Sprintf(bm.Words["requestNotFound"], bm.At(u.User), id))
+		return true
+	}
+
+	ru := &Update{
+		Type: r.Type,
+		Chat: &Chat{
+			ID:   r.ChatID,
+			Type: r.ChatType,
+		},
+		User: &User{
+			ID:       r.UserID,
+			NickName: r.NickName,
+		},
+		Request: &Request{
+			ID:      id,
+			SubType: r.SubType,
+			Comment: r.Comment,
+		},
+		Bot: u.Bot,
+	}
+	ru.link()
+
+	approve := f.Args()[1] == "approve"
+	err := bm.Approve(ru, approve, strings.Join(f.Args()[3:], " "))
+	if err != nil {
+		bm.Reply(u, err.Error())
+		return true
+	}
+
+	bm.Store.HDel("request_"+u.Bot.ID, id)
+
+	if approve {
+		bm.Reply(u, fmt.Sprintf(bm.Words["requestApproved"], id))
+	} else {
+		bm.Reply(u, fmt.Sprintf(bm.Words["requestRejected"], id))
+	}
+	return true
+}
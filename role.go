@@ -0,0 +1,188 @@
+package botmaid
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Built-in roles, a user with a role also has the built-in roles lower than it. Masters have all roles.
+//
+// RoleChatAdmin is also held by owners and admins of a chat on the platform, see IsChatAdmin.
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleChatAdmin = "chatadmin"
+	RoleModerator = "moderator"
+)
+
+var (
+	roleLevels = map[string]int{
+		RoleModerator: 1,
+		RoleChatAdmin: 2,
+		RoleAdmin:     3,
+		RoleOwner:     4,
+	}
+)
+
+func roleKey(botID, role string, chatID int64) string {
+	if chatID == 0 {
+		return "role_" + botID + "_" + role
+	}
+	return fmt.Sprintf("role_%v_%v_%v", botID, role, chatID)
+}
+
+// GrantRole grants a role to a user in a chat, or in all chats if chatID is 0.
+func (bm *BotMaid) GrantRole(botID, role string, userID, chatID int64) {
+	bm.Store.SAdd(roleKey(botID, role, chatID), userID)
+	bm.Store.SAdd("roles_"+botID, role)
+}
+
+// RevokeRole revokes a role granted to a user in a chat, or in all chats if chatID is 0.
+func (bm *BotMaid) RevokeRole(botID, role string, userID, chatID int64) {
+	bm.Store.SRem(roleKey(botID, role, chatID), userID)
+}
+
+func (bm *BotMaid) hasGrantedRole(u *Update, role string) bool {
+	if is, _ := bm.Store.SIsMember(roleKey(u.Bot.ID, role, 0), u.User.ID); is {
+		return true
+	}
+	if u.Chat != nil {
+		if is, _ := bm.Store.SIsMember(roleKey(u.Bot.ID, role, u.Chat.ID), u.User.ID); is {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole checks if the user of an update has a role in the chat of the update.
+func (bm *BotMaid) HasRole(u *Update, role string) bool {
+	if role == "" {
+		return true
+	}
+	if u.User == nil {
+		return false
+	}
+	if bm.IsMaster(u.User) {
+		return true
+	}
+
+	level, ok := roleLevels[role]
+	if !ok {
+		return bm.hasGrantedRole(u, role)
+	}
+
+	for r, l := range roleLevels {
+		if l >= level && bm.hasGrantedRole(u, r) {
+			return true
+		}
+	}
+	return level <= roleLevels[RoleChatAdmin] && bm.IsChatAdmin(u)
+}
+
+// requireRole replies noPermission and returns false if the user of an update doesn't have a role, built-in commands check it by themselves so that they are safe without Permission.
+func (bm *BotMaid) requireRole(u *Update, role string) bool {
+	if bm.HasRole(u, role) {
+		return true
+	}
+
+	if u.User != nil {
+		bm.Reply(u, fmt.Sprintf(bm.Words["noPermission"], bm.At(u.User), u.Message.Command))
+	}
+	return false
+}
+
+// requireMaster replies noPermission and returns false if the user of an update is not a master.
+func (bm *BotMaid) requireMaster(u *Update) bool {
+	if u.User != nil && bm.IsMaster(u.User) {
+		return true
+	}
+
+	if u.User != nil {
+		bm.Reply(u, fmt.Sprintf(bm.Words["noPermission"], bm.At(u.User), u.Message.Command))
+	}
+	return false
+}
+
+// rolesOf returns the roles granted to a user, roles granted in a chat are followed by the chat ID.
+func (bm *BotMaid) rolesOf(u *Update, userID int64) []string {
+	roles, _ := bm.Store.SMembers("roles_" + u.Bot.ID)
+
+	ret := []string{}
+	for _, r := range roles {
+		if is, _ := bm.Store.SIsMember(roleKey(u.Bot.ID, r, 0), userID); is {
+			ret = append(ret, r)
+		}
+		if u.Chat != nil {
+			if is, _ := bm.Store.SIsMember(roleKey(u.Bot.ID, r, u.Chat.ID), userID); is {
+				ret = append(ret, fmt.Sprintf("%v(%v)", r, u.Chat.ID))
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// RoleCommandDo grants, revokes or lists roles. Owners could manage the roles in their chats with --chat, and only masters could manage the roles in all chats.
+func (bm *BotMaid) RoleCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if !bm.requireRole(u, RoleOwner) {
+		return true
+	}
+
+	if len(f.Args()) == 3 && f.Args()[1] == "list" {
+		id, err := (*u.Bot.API).ParseUserID(u, f.Args()[2])
+		if err != nil {
+			id, err = strconv.ParseInt(f.Args()[2], 10, 64)
+		}
+		if err != nil {
+			bm.Reply(u, fmt.Sprintf(bm.Words["invalidUser"], bm.At(u.User), f.Args()[2]))
+			return true
+		}
+
+		roles := bm.rolesOf(u, id)
+		if len(roles) == 0 {
+			bm.Reply(u, fmt.Sprintf(bm.Words["noRole"], f.Args()[2]))
+			return true
+		}
+		bm.Reply(u, fmt.Sprintf(bm.Words["roleList"], f.Args()[2], strings.Join(roles, ", ")))
+		return true
+	}
+
+	if len(f.Args()) != 4 || (f.Args()[1] != "grant" && f.Args()[1] != "revoke") {
+		return false
+	}
+
+	role := f.Args()[2]
+	id, err := (*u.Bot.API).ParseUserID(u, f.Args()[3])
+	if err != nil {
+		id, err = strconv.ParseInt(f.Args()[3], 10, 64)
+	}
+	if err != nil {
+		bm.Reply(u, fmt.Sprintf(bm.Words["invalidUser"], bm.At(u.User), f.Args()[3]))
+		return true
+	}
+
+	chatID := int64(0)
+	if inChat, _ := f.GetBool("chat"); inChat && u.Chat != nil {
+		chatID = u.Chat.ID
+	} else if !bm.requireMaster(u) {
+		return true
+	}
+
+	if f.Args()[1] == "grant" {
+		bm.GrantRole(u.Bot.ID, role, id, chatID)
+		bm.Reply(u, fmt.Sprintf(bm.Words["roleGranted"], role, f.Args()[3]))
+		return true
+	}
+
+	bm.RevokeRole(u.Bot.ID, role, id, chatID)
+	bm.Reply(u, fmt.Sprintf(bm.Words["roleRevoked"], role, f.Args()[3]))
+	return true
+}
+
+func (bm *BotMaid) RoleCommandHelpSetFlag(f *pflag.FlagSet) {
+	f.BoolP("chat", "c", false, bm.Words["roleChatHelp"])
+}
@@ -0,0 +1,93 @@
+package botmaid_test
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestPermission(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, "done")
+			return true
+		},
+		Permission: botmaid.RoleAdmin,
+		Help: &botmaid.Help{
+			Menu:  "admin",
+			Names: []string{"admin"},
+		},
+	})
+
+	expectReplies(t, "/admin", h.Say("/admin"), `@user, you don't have permission to use "admin"`)
+
+	h.BotMaid.GrantRole(h.Bot.ID, botmaid.RoleModerator, 1, 0)
+	expectReplies(t, "/admin by a moderator", h.Say("/admin"), `@user, you don't have permission to use "admin"`)
+
+	h.BotMaid.GrantRole(h.Bot.ID, botmaid.RoleOwner, 2, 2)
+	expectReplies(t, "/admin by an owner of the chat", sayAs(h, 2, "/admin"), "done")
+	h.BotMaid.GrantRole(h.Bot.ID, botmaid.RoleOwner, 3, 2)
+	expectReplies(t, "/admin by an owner of another chat", sayAs(h, 3, "/admin"), `@3, you don't have permission to use "admin"`)
+
+	addMaster(h, 1)
+	expectReplies(t, "/admin by a master", h.Say("/admin"), "done")
+
+	u := h.NewMessage("/admin")
+	u.Type = botmaid.UpdateChannelPost
+	u.User = nil
+	u.Chat.Type = "channel"
+	expectReplies(t, "/admin without a user", texts(h.Send(u)))
+}
+
+func TestBuiltinCommandsWithoutPermission(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.Conf.Ban.ReplyLoop = 0
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: h.BotMaid.VersetCommandDo,
+		Help: &botmaid.Help{
+			Menu:    "verset",
+			Names:   []string{"verset"},
+			SetFlag: h.BotMaid.VersetCommandHelpSetFlag,
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: h.BotMaid.MasterCommandDo,
+		Help: &botmaid.Help{
+			Menu:  "master",
+			Names: []string{"master"},
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: h.BotMaid.RoleCommandDo,
+		Help: &botmaid.Help{
+			Menu:    "role",
+			Names:   []string{"role"},
+			SetFlag: h.BotMaid.RoleCommandHelpSetFlag,
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: h.BotMaid.BanCommandDo,
+		Help: &botmaid.Help{
+			Menu:    "ban",
+			Names:   []string{"ban"},
+			SetFlag: h.BotMaid.BanCommandHelpSetFlag,
+		},
+	})
+
+	expectReplies(t, "/verset 9.9", h.Say("/verset 9.9"), `@user, you don't have permission to use "verset"`)
+	if v, _ := h.Store.Get("version"); v != "" {
+		t.Errorf("version: %q, want empty", v)
+	}
+	expectReplies(t, "/ban 2", h.Say("/ban 2"), `@user, you don't have permission to use "ban"`)
+	expectReplies(t, "/role grant owner 1", h.Say("/role grant owner 1"), `@user, you don't have permission to use "role"`)
+
+	h.BotMaid.GrantRole(h.Bot.ID, botmaid.RoleOwner, 1, 1)
+	expectReplies(t, "/master 1 by an owner of the chat", h.Say("/master 1"), `@user, you don't have permission to use "master"`)
+	expectReplies(t, "/role grant owner 2 by an owner of the chat", h.Say("/role grant owner 2"), `@user, you don't have permission to use "role"`)
+	expectReplies(t, "/role -c grant admin 2 by an owner of the chat", h.Say("/role -c grant admin 2"), "The role admin has been granted to 2.")
+
+	addMaster(h, 1)
+	expectReplies(t, "/verset 9.9 by a master", h.Say("/verset 9.9"), "The version has been set to 9.9.")
+}
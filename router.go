@@ -0,0 +1,60 @@
+package botmaid
+
+import (
+	"sort"
+)
+
+// Route is a handler with some filters deciding which updates it handles, an empty filter matches all updates.
+//
+// The chat type "group" also matches the "supergroup" of Telegram and the "discuss" of QQ.
+// Routes with higher Priority are tried first, the default routes of callback handlers and commands have the priority 0.
+type Route struct {
+	Types     []string
+	ChatTypes []string
+	Platforms []string
+	BotIDs    []string
+
+	Priority int
+
+	Do func(*Update) bool
+}
+
+func (r *Route) matches(u *Update) bool {
+	if len(r.Types) != 0 && !Contains(r.Types, u.Type) {
+		return false
+	}
+
+	if len(r.ChatTypes) != 0 {
+		if u.Chat == nil {
+			return false
+		}
+
+		t := u.Chat.Type
+		if t == "supergroup" || t == "discuss" {
+			if !Contains(r.ChatTypes, t) && !Contains(r.ChatTypes, "group") {
+				return false
+			}
+		} else if !Contains(r.ChatTypes, t) {
+			return false
+		}
+	}
+
+	if len(r.Platforms) != 0 && !Contains(r.Platforms, (*u.Bot.API).Platform()) {
+		return false
+	}
+
+	if len(r.BotIDs) != 0 && !Contains(r.BotIDs, u.Bot.ID) {
+		return false
+	}
+
+	return true
+}
+
+// Handle adds a route, routes with the same priority are tried in the order of adding.
+func (bm *BotMaid) Handle(r *Route) {
+	bm.Routes = append(bm.Routes, r)
+
+	sort.SliceStable(bm.Routes, func(i, j int) bool {
+		return bm.Routes[i].Priority > bm.Routes[j].Priority
+	})
+}
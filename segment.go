@@ -0,0 +1,76 @@
+package botmaid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Types of segments.
+const (
+	SegmentText    = "text"
+	SegmentMention = "mention"
+	SegmentImage   = "image"
+	SegmentAudio   = "audio"
+	SegmentVideo   = "video"
+	SegmentFile    = "file"
+	SegmentReply   = "reply"
+	SegmentEmoji   = "emoji"
+	SegmentLink    = "link"
+)
+
+// Segment is a part of a message.
+//
+// Text is the text of a text segment, the display name of a mention, the emoji of an emoji segment or the title of a link.
+// UserID is the ID of the mentioned user.
+// MessageID is the ID of the replied message.
+// File is the URL, the local path or the platform-specific ID of an image, audio, video, file or emoji.
+// URL is the URL of a link.
+type Segment struct {
+	Type string
+
+	Text      string
+	UserID    int64
+	MessageID int64
+	File      string
+	URL       string
+}
+
+// PlainText returns the message in plain text without any platform markup, mentions are shown as "@" followed by the ID of the user, or by the user name if the ID is unknown, and files are omitted.
+func (m *Message) PlainText() string {
+	if len(m.Segments) == 0 {
+		return m.Content
+	}
+
+	s := ""
+	for _, v := range m.Segments {
+		switch v.Type {
+		case SegmentText, SegmentEmoji:
+			s += v.Text
+		case SegmentMention:
+			if v.UserID != 0 {
+				s += fmt.Sprintf("@%v", v.UserID)
+			} else {
+				s += "@" + v.Text
+			}
+		case SegmentLink:
+			if v.Text != "" && v.Text != v.URL {
+				s += v.Text + " "
+			}
+			s += v.URL
+		}
+	}
+	return s
+}
+
+// excerpt returns the beginning of a string with at most 50 characters.
+func excerpt(s string) string {
+	rs := []rune(strings.TrimSpace(s))
+	if len(rs) > 50 {
+		return string(rs[:50]) + "..."
+	}
+	return string(rs)
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
@@ -0,0 +1,180 @@
+package botmaid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCQSegments(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Segment
+	}{
+		{"", []Segment{}},
+		{"hello &#91;world&#93; &amp; all", []Segment{
+			{Type: SegmentText, Text: "hello [world] & all"},
+		}},
+		{"[CQ:reply,id=5]hi [CQ:at,qq=123] and [CQ:at,qq=all]", []Segment{
+			{Type: SegmentReply, MessageID: 5},
+			{Type: SegmentText, Text: "hi "},
+			{Type: SegmentMention, UserID: 123},
+			{Type: SegmentText, Text: " and "},
+			{Type: SegmentMention, Text: "all"},
+		}},
+		{"[CQ:image,file=a.jpg,url=http://example.com/a.jpg?x=1&amp;y=2][CQ:record,file=b.amr][CQ:video,file=c.mp4]", []Segment{
+			{Type: SegmentImage, File: "http://example.com/a.jpg?x=1&y=2"},
+			{Type: SegmentAudio, File: "b.amr"},
+			{Type: SegmentVideo, File: "c.mp4"},
+		}},
+		{"[CQ:face,id=14][CQ:share,url=http://example.com,title=a&#44;b][CQ:unknown,a=b]end", []Segment{
+			{Type: SegmentEmoji, File: "14"},
+			{Type: SegmentLink, URL: "http://example.com", Text: "a,b"},
+			{Type: SegmentText, Text: "end"},
+		}},
+		{"broken [CQ:at,qq=1", []Segment{
+			{Type: SegmentText, Text: "broken [CQ:at,qq=1"},
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := parseCQSegments(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCQSegments(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderCQSegments(t *testing.T) {
+	a := &APICqhttp{}
+	ss := []Segment{
+		{Type: SegmentText, Text: "[a] & "},
+		{Type: SegmentMention, UserID: 123},
+		{Type: SegmentMention, Text: "all"},
+		{Type: SegmentImage, File: "http://example.com/a.jpg"},
+		{Type: SegmentReply, MessageID: 5},
+	}
+
+	got, err := a.renderSegments(ss)
+	if err != nil {
+		t.Fatalf("renderSegments: %v", err)
+	}
+	want := "[CQ:reply,id=5]&#91;a&#93; &amp; [CQ:at,qq=123][CQ:at,qq=all][CQ:image,file=http://example.com/a.jpg]"
+	if got != want {
+		t.Errorf("renderSegments = %q, want %q", got, want)
+	}
+
+	if back := parseCQSegments(got); !reflect.DeepEqual(back[1:], []Segment{
+		{Type: SegmentText, Text: "[a] & "},
+		{Type: SegmentMention, UserID: 123},
+		{Type: SegmentMention, Text: "all"},
+		{Type: SegmentImage, File: "http://example.com/a.jpg"},
+	}) {
+		t.Errorf("parseCQSegments(renderSegments) = %+v", back)
+	}
+}
+
+func TestTelegramTextSegments(t *testing.T) {
+	text := "😀 @alice hi Bob see https://example.com and docs"
+	es := []interface{}{
+		map[string]interface{}{"type": "mention", "offset": 3.0, "length": 6.0},
+		map[string]interface{}{"type": "text_mention", "offset": 13.0, "length": 3.0, "user": map[string]interface{}{
+			"id":         42.0,
+			"first_name": "Bob",
+			"last_name":  "Smith",
+		}},
+		map[string]interface{}{"type": "bold", "offset": 17.0, "length": 3.0},
+		map[string]interface{}{"type": "url", "offset": 21.0, "length": 19.0},
+		map[string]interface{}{"type": "text_link", "offset": 45.0, "length": 4.0, "url": "https://example.com/docs"},
+	}
+
+	want := []Segment{
+		{Type: SegmentText, Text: "😀 "},
+		{Type: SegmentMention, Text: "alice"},
+		{Type: SegmentText, Text: " hi "},
+		{Type: SegmentMention, UserID: 42, Text: "Bob Smith"},
+		{Type: SegmentText, Text: " see "},
+		{Type: SegmentLink, Text: "https://example.com", URL: "https://example.com"},
+		{Type: SegmentText, Text: " and "},
+		{Type: SegmentLink, Text: "docs", URL: "https://example.com/docs"},
+	}
+
+	if got := telegramTextSegments(text, es); !reflect.DeepEqual(got, want) {
+		t.Errorf("telegramTextSegments = %+v, want %+v", got, want)
+	}
+
+	if got := telegramTextSegments("hi", []interface{}{
+		map[string]interface{}{"type": "mention", "offset": 1.0, "length": 5.0},
+	}); !reflect.DeepEqual(got, []Segment{{Type: SegmentText, Text: "hi"}}) {
+		t.Errorf("telegramTextSegments with an entity out of range = %+v", got)
+	}
+}
+
+func TestTelegramSegments(t *testing.T) {
+	m := map[string]interface{}{
+		"reply_to_message": map[string]interface{}{"message_id": 7.0},
+		"photo": []interface{}{
+			map[string]interface{}{"file_id": "small"},
+			map[string]interface{}{"file_id": "large"},
+		},
+		"caption": "look @bob",
+		"caption_entities": []interface{}{
+			map[string]interface{}{"type": "mention", "offset": 5.0, "length": 4.0},
+		},
+	}
+
+	want := []Segment{
+		{Type: SegmentReply, MessageID: 7},
+		{Type: SegmentImage, File: "large"},
+		{Type: SegmentText, Text: "look "},
+		{Type: SegmentMention, Text: "bob"},
+	}
+	if got := telegramSegments(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("telegramSegments(photo) = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		m    map[string]interface{}
+		want []Segment
+	}{
+		{map[string]interface{}{"voice": map[string]interface{}{"file_id": "v"}}, []Segment{{Type: SegmentAudio, File: "v"}}},
+		{map[string]interface{}{"video": map[string]interface{}{"file_id": "v"}}, []Segment{{Type: SegmentVideo, File: "v"}}},
+		{map[string]interface{}{
+			"animation": map[string]interface{}{"file_id": "a"},
+			"document":  map[string]interface{}{"file_id": "a"},
+		}, []Segment{{Type: SegmentVideo, File: "a"}}},
+		{map[string]interface{}{"document": map[string]interface{}{"file_id": "d"}}, []Segment{{Type: SegmentFile, File: "d"}}},
+		{map[string]interface{}{"sticker": map[string]interface{}{"file_id": "s", "emoji": "😀"}}, []Segment{{Type: SegmentEmoji, File: "s", Text: "😀"}}},
+	}
+	for _, tt := range tests {
+		if got := telegramSegments(tt.m); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("telegramSegments(%v) = %+v, want %+v", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	m := &Message{
+		Content: "ignored",
+		Segments: []Segment{
+			{Type: SegmentReply, MessageID: 1},
+			{Type: SegmentMention, UserID: 42, Text: "Bob"},
+			{Type: SegmentText, Text: " hi "},
+			{Type: SegmentMention, Text: "alice"},
+			{Type: SegmentImage, File: "a.jpg"},
+			{Type: SegmentEmoji, Text: "😀"},
+			{Type: SegmentText, Text: " "},
+			{Type: SegmentLink, Text: "docs", URL: "https://example.com"},
+			{Type: SegmentText, Text: " "},
+			{Type: SegmentLink, Text: "https://example.com", URL: "https://example.com"},
+		},
+	}
+
+	want := "@42 hi @alice😀 docs https://example.com https://example.com"
+	if got := m.PlainText(); got != want {
+		t.Errorf("PlainText = %q, want %q", got, want)
+	}
+
+	m = &Message{Content: "plain"}
+	if got := m.PlainText(); got != "plain" {
+		t.Errorf("PlainText without segments = %q, want %q", got, "plain")
+	}
+}
@@ -0,0 +1,138 @@
+package botmaid
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Session is a conversation waiting for the next message of a user in a chat, which is answered to the session handler named Handler.
+//
+// Data is kept between the steps of the conversation. The session expires after Timeout, or Session.Timeout in the config if it is 0.
+type Session struct {
+	Handler string
+	Data    map[string]string
+
+	Timeout time.Duration
+	Until   time.Time
+}
+
+// SessionHandler handles the answers of sessions.
+//
+// Validate, if not nil, checks an answer before Do, the question is asked again with the error if it returns one.
+// Do could call Ask with the session to ask the next question.
+type SessionHandler struct {
+	Validate func(u *Update, answer string) error
+	Do       func(u *Update, s *Session, answer string)
+}
+
+func sessionField(u *Update) string {
+	return fmt.Sprintf("%v_%v", u.Chat.ID, u.User.ID)
+}
+
+// AddSessionHandler adds a session handler with a name, which is saved in sessions instead of the handler so that they survive restarting.
+func (bm *BotMaid) AddSessionHandler(name string, h *SessionHandler) {
+	bm.SessionHandlers[name] = h
+}
+
+// Ask replies a prompt and starts a session, the next message of the user in the chat of the update is answered to the session.
+func (bm *BotMaid) Ask(u *Update, prompt string, s *Session) error {
+	if _, ok := bm.SessionHandlers[s.Handler]; !ok {
+		return fmt.Errorf("Ask: Undefined session handler %v", s.Handler)
+	}
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = bm.Conf.Session.Timeout
+	}
+	s.Until = time.Now().Add(timeout)
+
+	err := bm.saveSession(u, s)
+	if err != nil {
+		return err
+	}
+
+	_, err = bm.Reply(u, prompt)
+	return err
+}
+
+func (bm *BotMaid) saveSession(u *Update, s *Session) error {
+	j, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("Save session: %v", err)
+	}
+	return bm.Store.HSet("session_"+u.Bot.ID, sessionField(u), string(j))
+}
+
+// getSession returns the unexpired session of the user in the chat of an update, or nil if there is none.
+func (bm *BotMaid) getSession(u *Update) *Session {
+	v, _ := bm.Store.HGet("session_"+u.Bot.ID, sessionField(u))
+	if v == "" {
+		return nil
+	}
+
+	s := &Session{}
+	if json.Unmarshal([]byte(v), s) != nil || time.Now().After(s.Until) {
+		bm.EndSession(u)
+		return nil
+	}
+	return s
+}
+
+// EndSession ends the session of the user in the chat of an update.
+func (bm *BotMaid) EndSession(u *Update) {
+	bm.Store.HDel("session_"+u.Bot.ID, sessionField(u))
+}
+
+func (bm *BotMaid) isCancel(s string) bool {
+	for _, v := range bm.Conf.Session.Cancel {
+		if s == v {
+			return true
+		}
+		for _, p := range bm.Conf.CommandPrefix {
+			if s == p+v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SessionMiddleware answers messages to the sessions waiting for them, a message matching Session.Cancel in the config cancels the session. It is used by default.
+func (bm *BotMaid) SessionMiddleware(next Handler) Handler {
+	return func(u *Update) bool {
+		if u.Type != UpdateMessage || u.Message == nil || u.Chat == nil || u.User == nil {
+			return next(u)
+		}
+
+		s := bm.getSession(u)
+		if s == nil {
+			return next(u)
+		}
+
+		h, ok := bm.SessionHandlers[s.Handler]
+		if !ok {
+			bm.EndSession(u)
+			return next(u)
+		}
+
+		answer := strings.TrimSpace(u.Message.PlainText())
+		if bm.isCancel(answer) {
+			bm.EndSession(u)
+			bm.Reply(u, fmt.Sprintf(bm.Words["sessionCanceled"], bm.At(u.User)))
+			return true
+		}
+
+		if h.Validate != nil {
+			if err := h.Validate(u, answer); err != nil {
+				bm.Reply(u, fmt.Sprintf(bm.Words["invalidAnswer"], bm.At(u.User), err))
+				return true
+			}
+		}
+
+		bm.EndSession(u)
+		h.Do(u, s, answer)
+		return true
+	}
+}
@@ -0,0 +1,49 @@
+package botmaid_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestSession(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.Conf.Ban.ReplyLoop = 0
+	h.BotMaid.AddSessionHandler("age", &botmaid.SessionHandler{
+		Validate: func(u *botmaid.Update, answer string) error {
+			if _, err := strconv.Atoi(answer); err != nil {
+				return errors.New("the age should be a number")
+			}
+			return nil
+		},
+		Do: func(u *botmaid.Update, s *botmaid.Session, answer string) {
+			h.BotMaid.Reply(u, s.Data["name"]+" is "+answer)
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Ask(u, "How old?", &botmaid.Session{
+				Handler: "age",
+				Data:    map[string]string{"name": "Alice"},
+			})
+			return true
+		},
+		Help: &botmaid.Help{
+			Menu:  "ask",
+			Names: []string{"ask"},
+		},
+	})
+
+	expectReplies(t, "/ask", h.Say("/ask"), "How old?")
+	expectReplies(t, "3 in another chat", sayAs(h, 2, "3"))
+	expectReplies(t, "x", h.Say("x"), "@user, the age should be a number, please answer again.")
+	expectReplies(t, "3", h.Say("3"), "Alice is 3")
+	expectReplies(t, "3 after the session", h.Say("3"))
+
+	expectReplies(t, "/ask", h.Say("/ask"), "How old?")
+	expectReplies(t, "cancel", h.Say("cancel"), "@user, the conversation has been canceled.")
+	expectReplies(t, "3 after canceling", h.Say("3"))
+}
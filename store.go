@@ -0,0 +1,35 @@
+package botmaid
+
+import (
+	"time"
+)
+
+// Store is an interface including some common behaviors for storages.
+//
+// Get and HGet return an empty string if the key or field does not exist.
+// Incr increases the integer value of a string key by one, the key is set to 0 before increasing if it does not exist.
+// Expire and TTL set and return the expiration of a string key, TTL returns 0 if the key does not exist or has no expiration.
+type Store interface {
+	Get(key string) (string, error)
+	Set(key string, value interface{}, expiration time.Duration) error
+	Del(key string) error
+
+	Incr(key string) (int64, error)
+	Expire(key string, expiration time.Duration) error
+	TTL(key string) (time.Duration, error)
+
+	SAdd(key string, member interface{}) error
+	SRem(key string, member interface{}) error
+	SIsMember(key string, member interface{}) (bool, error)
+	SMembers(key string) ([]string, error)
+
+	HGet(key, field string) (string, error)
+	HSet(key, field string, value interface{}) error
+	HDel(key, field string) error
+	HGetAll(key string) (map[string]string, error)
+
+	RPush(key string, value interface{}) error
+	LRange(key string, start, stop int64) ([]string, error)
+
+	Ping() error
+}
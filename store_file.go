@@ -0,0 +1,199 @@
+package botmaid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileStore is a Store saving data in a single JSON file. Changes are saved together after SaveDelay, and the file is only rewritten if the data changed.
+type FileStore struct {
+	*MemoryStore
+
+	Path string
+	// SaveDelay is how long a change waits before being saved, changes in the delay are saved together. Changes are saved immediately if it's not positive.
+	SaveDelay time.Duration
+
+	saveMu sync.Mutex
+	saved  []byte
+
+	timerMu sync.Mutex
+	timer   *time.Timer
+	err     error
+}
+
+const defaultFileStoreSaveDelay = time.Second
+
+// NewFileStore creates a FileStore and loads the data in the file if it exists.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		Path:        path,
+		SaveDelay:   defaultFileStoreSaveDelay,
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Load store file: %v", err)
+	}
+
+	err = json.Unmarshal(raw, &s.data)
+	if err != nil {
+		return nil, fmt.Errorf("Load store file: %v", err)
+	}
+
+	if s.data.Strings == nil {
+		s.data.Strings = map[string]string{}
+	}
+	if s.data.Sets == nil {
+		s.data.Sets = map[string]map[string]bool{}
+	}
+	if s.data.Hashes == nil {
+		s.data.Hashes = map[string]map[string]string{}
+	}
+	if s.data.Lists == nil {
+		s.data.Lists = map[string][]string{}
+	}
+	if s.data.Expires == nil {
+		s.data.Expires = map[string]time.Time{}
+	}
+
+	return s, nil
+}
+
+func (s *FileStore) save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	s.purge()
+	raw, err := json.Marshal(&s.data)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("Save store file: %v", err)
+	}
+	if bytes.Equal(raw, s.saved) {
+		return nil
+	}
+
+	err = ioutil.WriteFile(s.Path+".tmp", raw, 0600)
+	if err != nil {
+		return fmt.Errorf("Save store file: %v", err)
+	}
+
+	err = os.Rename(s.Path+".tmp", s.Path)
+	if err != nil {
+		return fmt.Errorf("Save store file: %v", err)
+	}
+
+	s.saved = raw
+	return nil
+}
+
+// changed schedules a save after SaveDelay, and returns the error of the last scheduled save.
+func (s *FileStore) changed() error {
+	if s.SaveDelay <= 0 {
+		return s.save()
+	}
+
+	s.timerMu.Lock()
+	defer s.timerMu.Unlock()
+
+	err := s.err
+	s.err = nil
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.SaveDelay, func() {
+			s.timerMu.Lock()
+			s.timer = nil
+			s.timerMu.Unlock()
+
+			err := s.save()
+
+			s.timerMu.Lock()
+			s.err = err
+			s.timerMu.Unlock()
+		})
+	}
+	return err
+}
+
+// Flush saves the changes waiting for SaveDelay immediately.
+func (s *FileStore) Flush() error {
+	s.timerMu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.timerMu.Unlock()
+
+	return s.save()
+}
+
+// Set sets the string value of the key with an expiration, 0 means no expiration.
+func (s *FileStore) Set(key string, value interface{}, expiration time.Duration) error {
+	s.MemoryStore.Set(key, value, expiration)
+	return s.changed()
+}
+
+// Incr increases the integer value of the key by one.
+func (s *FileStore) Incr(key string) (int64, error) {
+	n, err := s.MemoryStore.Incr(key)
+	if err != nil {
+		return 0, err
+	}
+	return n, s.changed()
+}
+
+// Expire sets an expiration of the key.
+func (s *FileStore) Expire(key string, expiration time.Duration) error {
+	s.MemoryStore.Expire(key, expiration)
+	return s.changed()
+}
+
+// Del deletes the key.
+func (s *FileStore) Del(key string) error {
+	s.MemoryStore.Del(key)
+	return s.changed()
+}
+
+// SAdd adds a member into the set.
+func (s *FileStore) SAdd(key string, member interface{}) error {
+	s.MemoryStore.SAdd(key, member)
+	return s.changed()
+}
+
+// SRem removes a member from the set.
+func (s *FileStore) SRem(key string, member interface{}) error {
+	s.MemoryStore.SRem(key, member)
+	return s.changed()
+}
+
+// HSet sets the value of a field in the hash.
+func (s *FileStore) HSet(key, field string, value interface{}) error {
+	s.MemoryStore.HSet(key, field, value)
+	return s.changed()
+}
+
+// HDel deletes a field from the hash.
+func (s *FileStore) HDel(key, field string) error {
+	s.MemoryStore.HDel(key, field)
+	return s.changed()
+}
+
+// RPush appends a value to the list.
+func (s *FileStore) RPush(key string, value interface{}) error {
+	s.MemoryStore.RPush(key, value)
+	return s.changed()
+}
+
+// Ping checks if the file is writable, the changes waiting for SaveDelay are saved.
+func (s *FileStore) Ping() error {
+	return s.Flush()
+}
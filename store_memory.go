@@ -0,0 +1,255 @@
+package botmaid
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memoryData struct {
+	Strings map[string]string
+	Sets    map[string]map[string]bool
+	Hashes  map[string]map[string]string
+	Lists   map[string][]string
+	Expires map[string]time.Time
+}
+
+// MemoryStore is a Store saving data in the memory, all data will be lost after exiting.
+type MemoryStore struct {
+	mu   sync.Mutex
+	data memoryData
+}
+
+// NewMemoryStore creates a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: memoryData{
+			Strings: map[string]string{},
+			Sets:    map[string]map[string]bool{},
+			Hashes:  map[string]map[string]string{},
+			Lists:   map[string][]string{},
+			Expires: map[string]time.Time{},
+		},
+	}
+}
+
+func (s *MemoryStore) expire(key string) {
+	if t, ok := s.data.Expires[key]; ok && time.Now().After(t) {
+		delete(s.data.Strings, key)
+		delete(s.data.Expires, key)
+	}
+}
+
+// purge deletes all expired keys.
+func (s *MemoryStore) purge() {
+	for key := range s.data.Expires {
+		s.expire(key)
+	}
+}
+
+// Get returns the string value of the key.
+func (s *MemoryStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(key)
+	return s.data.Strings[key], nil
+}
+
+// Set sets the string value of the key with an expiration, 0 means no expiration.
+func (s *MemoryStore) Set(key string, value interface{}, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Strings[key] = fmt.Sprint(value)
+	delete(s.data.Expires, key)
+	if expiration > 0 {
+		s.data.Expires[key] = time.Now().Add(expiration)
+	}
+	return nil
+}
+
+// Del deletes the key.
+func (s *MemoryStore) Del(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data.Strings, key)
+	delete(s.data.Sets, key)
+	delete(s.data.Hashes, key)
+	delete(s.data.Lists, key)
+	delete(s.data.Expires, key)
+	return nil
+}
+
+// Incr increases the integer value of the key by one.
+func (s *MemoryStore) Incr(key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(key)
+	n := int64(0)
+	if v, ok := s.data.Strings[key]; ok {
+		var err error
+		n, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Incr %v: %v", key, err)
+		}
+	}
+	n++
+	s.data.Strings[key] = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+// Expire sets an expiration of the key.
+func (s *MemoryStore) Expire(key string, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(key)
+	if _, ok := s.data.Strings[key]; ok {
+		s.data.Expires[key] = time.Now().Add(expiration)
+	}
+	return nil
+}
+
+// TTL returns the remaining time to live of the key.
+func (s *MemoryStore) TTL(key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(key)
+	if t, ok := s.data.Expires[key]; ok {
+		return time.Until(t), nil
+	}
+	return 0, nil
+}
+
+// SAdd adds a member into the set.
+func (s *MemoryStore) SAdd(key string, member interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data.Sets[key] == nil {
+		s.data.Sets[key] = map[string]bool{}
+	}
+	s.data.Sets[key][fmt.Sprint(member)] = true
+	return nil
+}
+
+// SRem removes a member from the set.
+func (s *MemoryStore) SRem(key string, member interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data.Sets[key], fmt.Sprint(member))
+	if len(s.data.Sets[key]) == 0 {
+		delete(s.data.Sets, key)
+	}
+	return nil
+}
+
+// SIsMember checks if a member is in the set.
+func (s *MemoryStore) SIsMember(key string, member interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.Sets[key][fmt.Sprint(member)], nil
+}
+
+// SMembers returns all members of the set.
+func (s *MemoryStore) SMembers(key string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := []string{}
+	for m := range s.data.Sets[key] {
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// HGet returns the value of a field in the hash.
+func (s *MemoryStore) HGet(key, field string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.Hashes[key][field], nil
+}
+
+// HSet sets the value of a field in the hash.
+func (s *MemoryStore) HSet(key, field string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data.Hashes[key] == nil {
+		s.data.Hashes[key] = map[string]string{}
+	}
+	s.data.Hashes[key][field] = fmt.Sprint(value)
+	return nil
+}
+
+// HDel deletes a field from the hash.
+func (s *MemoryStore) HDel(key, field string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data.Hashes[key], field)
+	if len(s.data.Hashes[key]) == 0 {
+		delete(s.data.Hashes, key)
+	}
+	return nil
+}
+
+// HGetAll returns all fields and values of the hash.
+func (s *MemoryStore) HGetAll(key string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := map[string]string{}
+	for k, v := range s.data.Hashes[key] {
+		m[k] = v
+	}
+	return m, nil
+}
+
+// RPush appends a value to the list.
+func (s *MemoryStore) RPush(key string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.Lists[key] = append(s.data.Lists[key], fmt.Sprint(value))
+	return nil
+}
+
+// LRange returns the elements of the list in [start..stop], negative indexes count from the end.
+func (s *MemoryStore) LRange(key string, start, stop int64) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.data.Lists[key]
+	n := int64(len(l))
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return []string{}, nil
+	}
+
+	return append([]string{}, l[start:stop+1]...), nil
+}
+
+// Ping always succeeds.
+func (s *MemoryStore) Ping() error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package botmaid
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// RedisStore is a Store saving data in a Redis server.
+type RedisStore struct {
+	Client *redis.Client
+}
+
+// Get returns the string value of the key.
+func (s *RedisStore) Get(key string) (string, error) {
+	v, err := s.Client.Get(key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return v, err
+}
+
+// Set sets the string value of the key with an expiration, 0 means no expiration.
+func (s *RedisStore) Set(key string, value interface{}, expiration time.Duration) error {
+	return s.Client.Set(key, value, expiration).Err()
+}
+
+// Del deletes the key.
+func (s *RedisStore) Del(key string) error {
+	return s.Client.Del(key).Err()
+}
+
+// Incr increases the integer value of the key by one.
+func (s *RedisStore) Incr(key string) (int64, error) {
+	return s.Client.Incr(key).Result()
+}
+
+// Expire sets an expiration of the key.
+func (s *RedisStore) Expire(key string, expiration time.Duration) error {
+	return s.Client.Expire(key, expiration).Err()
+}
+
+// TTL returns the remaining time to live of the key.
+func (s *RedisStore) TTL(key string) (time.Duration, error) {
+	d, err := s.Client.TTL(key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, nil
+	}
+	return d, nil
+}
+
+// SAdd adds a member into the set.
+func (s *RedisStore) SAdd(key string, member interface{}) error {
+	return s.Client.SAdd(key, member).Err()
+}
+
+// SRem removes a member from the set.
+func (s *RedisStore) SRem(key string, member interface{}) error {
+	return s.Client.SRem(key, member).Err()
+}
+
+// SIsMember checks if a member is in the set.
+func (s *RedisStore) SIsMember(key string, member interface{}) (bool, error) {
+	return s.Client.SIsMember(key, member).Result()
+}
+
+// SMembers returns all members of the set.
+func (s *RedisStore) SMembers(key string) ([]string, error) {
+	return s.Client.SMembers(key).Result()
+}
+
+// HGet returns the value of a field in the hash.
+func (s *RedisStore) HGet(key, field string) (string, error) {
+	v, err := s.Client.HGet(key, field).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return v, err
+}
+
+// HSet sets the value of a field in the hash.
+func (s *RedisStore) HSet(key, field string, value interface{}) error {
+	return s.Client.HSet(key, field, value).Err()
+}
+
+// HDel deletes a field from the hash.
+func (s *RedisStore) HDel(key, field string) error {
+	return s.Client.HDel(key, field).Err()
+}
+
+// HGetAll returns all fields and values of the hash.
+func (s *RedisStore) HGetAll(key string) (map[string]string, error) {
+	return s.Client.HGetAll(key).Result()
+}
+
+// RPush appends a value to the list.
+func (s *RedisStore) RPush(key string, value interface{}) error {
+	return s.Client.RPush(key, value).Err()
+}
+
+// LRange returns the elements of the list in [start..stop], negative indexes count from the end.
+func (s *RedisStore) LRange(key string, start, stop int64) ([]string, error) {
+	return s.Client.LRange(key, start, stop).Result()
+}
+
+// Ping checks the connection to the Redis server.
+func (s *RedisStore) Ping() error {
+	return s.Client.Ping().Err()
+}
@@ -0,0 +1,249 @@
+package botmaid
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreStrings(t *testing.T) {
+	s := NewMemoryStore()
+
+	if v, err := s.Get("a"); err != nil || v != "" {
+		t.Fatalf("Get missing key: %q, %v", v, err)
+	}
+
+	s.Set("a", 1, 0)
+	if v, _ := s.Get("a"); v != "1" {
+		t.Fatalf("Get: %q, want %q", v, "1")
+	}
+	if d, _ := s.TTL("a"); d != 0 {
+		t.Fatalf("TTL without expiration: %v, want 0", d)
+	}
+
+	if n, err := s.Incr("a"); err != nil || n != 2 {
+		t.Fatalf("Incr: %v, %v, want 2", n, err)
+	}
+	if n, err := s.Incr("b"); err != nil || n != 1 {
+		t.Fatalf("Incr missing key: %v, %v, want 1", n, err)
+	}
+	s.Set("c", "x", 0)
+	if _, err := s.Incr("c"); err == nil {
+		t.Fatal("Incr non-integer value: no error")
+	}
+
+	s.Expire("a", time.Minute)
+	if d, _ := s.TTL("a"); d <= 0 || d > time.Minute {
+		t.Fatalf("TTL: %v, want in (0, 1m]", d)
+	}
+
+	s.Expire("missing", time.Minute)
+	if d, _ := s.TTL("missing"); d != 0 {
+		t.Fatalf("TTL of missing key: %v, want 0", d)
+	}
+
+	s.Set("d", "x", 20*time.Millisecond)
+	time.Sleep(40 * time.Millisecond)
+	if v, _ := s.Get("d"); v != "" {
+		t.Fatalf("Get expired key: %q, want empty", v)
+	}
+	if d, _ := s.TTL("d"); d != 0 {
+		t.Fatalf("TTL of expired key: %v, want 0", d)
+	}
+	if n, _ := s.Incr("d"); n != 1 {
+		t.Fatalf("Incr expired key: %v, want 1", n)
+	}
+
+	s.Del("a")
+	if v, _ := s.Get("a"); v != "" {
+		t.Fatalf("Get deleted key: %q, want empty", v)
+	}
+}
+
+func TestMemoryStoreSetsAndHashes(t *testing.T) {
+	s := NewMemoryStore()
+
+	s.SAdd("s", 1)
+	s.SAdd("s", "2")
+	if ok, _ := s.SIsMember("s", "1"); !ok {
+		t.Fatal("SIsMember: 1 is not a member")
+	}
+	s.SRem("s", 1)
+	if ok, _ := s.SIsMember("s", 1); ok {
+		t.Fatal("SIsMember: removed 1 is still a member")
+	}
+	if ms, _ := s.SMembers("s"); !reflect.DeepEqual(ms, []string{"2"}) {
+		t.Fatalf("SMembers: %v", ms)
+	}
+
+	s.HSet("h", "a", 1)
+	s.HSet("h", "b", "x")
+	if v, _ := s.HGet("h", "a"); v != "1" {
+		t.Fatalf("HGet: %q, want %q", v, "1")
+	}
+	if v, _ := s.HGet("h", "c"); v != "" {
+		t.Fatalf("HGet missing field: %q, want empty", v)
+	}
+	s.HDel("h", "a")
+	if m, _ := s.HGetAll("h"); !reflect.DeepEqual(m, map[string]string{"b": "x"}) {
+		t.Fatalf("HGetAll: %v", m)
+	}
+}
+
+func TestMemoryStoreLRange(t *testing.T) {
+	s := NewMemoryStore()
+	for _, v := range []string{"a", "b", "c", "d"} {
+		s.RPush("l", v)
+	}
+
+	tests := []struct {
+		start, stop int64
+		want        []string
+	}{
+		{0, -1, []string{"a", "b", "c", "d"}},
+		{1, 2, []string{"b", "c"}},
+		{-2, -1, []string{"c", "d"}},
+		{-100, 1, []string{"a", "b"}},
+		{2, 100, []string{"c", "d"}},
+		{3, 1, []string{}},
+		{-1, -2, []string{}},
+		{4, 10, []string{}},
+	}
+
+	for _, tt := range tests {
+		got, err := s.LRange("l", tt.start, tt.stop)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LRange(%v, %v) = %v, %v, want %v", tt.start, tt.stop, got, err, tt.want)
+		}
+	}
+
+	if got, _ := s.LRange("missing", 0, -1); len(got) != 0 {
+		t.Errorf("LRange of missing key: %v, want empty", got)
+	}
+}
+
+func TestFileStoreReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botmaid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	s.Set("a", "x", 0)
+	s.Set("b", "y", time.Hour)
+	s.Set("c", "z", 20*time.Millisecond)
+	s.Incr("n")
+	s.SAdd("s", 1)
+	s.HSet("h", "f", 2)
+	s.RPush("l", "a")
+	s.RPush("l", "b")
+
+	time.Sleep(40 * time.Millisecond)
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	s, err = NewFileStore(path)
+	if err != nil {
+		t.Fatalf("Reload FileStore: %v", err)
+	}
+	if v, _ := s.Get("a"); v != "x" {
+		t.Errorf("Get after reload: %q, want %q", v, "x")
+	}
+	if d, _ := s.TTL("b"); d <= 0 || d > time.Hour {
+		t.Errorf("TTL after reload: %v, want in (0, 1h]", d)
+	}
+	if v, _ := s.Get("c"); v != "" {
+		t.Errorf("Get expired key after reload: %q, want empty", v)
+	}
+	if v, _ := s.Get("n"); v != "1" {
+		t.Errorf("Get counter after reload: %q, want %q", v, "1")
+	}
+	if ok, _ := s.SIsMember("s", 1); !ok {
+		t.Error("SIsMember after reload: 1 is not a member")
+	}
+	if v, _ := s.HGet("h", "f"); v != "2" {
+		t.Errorf("HGet after reload: %q, want %q", v, "2")
+	}
+	if l, _ := s.LRange("l", 0, -1); !reflect.DeepEqual(l, []string{"a", "b"}) {
+		t.Errorf("LRange after reload: %v", l)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path); err == nil {
+		t.Error("NewFileStore with a broken file: no error")
+	}
+}
+
+func TestFileStoreSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "botmaid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	s.SaveDelay = 50 * time.Millisecond
+
+	s.Set("a", "x", 0)
+	s.Set("b", "y", 20*time.Millisecond)
+	s.SAdd("s", 1)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Stat before SaveDelay: %v, want not exist", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile after SaveDelay: %v", err)
+	}
+	var data memoryData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Strings["a"] != "x" || !data.Sets["s"]["1"] {
+		t.Errorf("saved data: %+v", data)
+	}
+	if _, ok := data.Strings["b"]; ok {
+		t.Error("saved data: the expired key b is not purged")
+	}
+	if _, ok := data.Expires["b"]; ok {
+		t.Error("saved data: the expiration of b is not purged")
+	}
+
+	os.Remove(path)
+	s.Set("a", "x", 0)
+	s.SAdd("s", 1)
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Stat after unchanged writes: %v, want not exist", err)
+	}
+
+	s.Set("a", "z", 0)
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if s, err = NewFileStore(path); err != nil {
+		t.Fatalf("Reload FileStore: %v", err)
+	}
+	if v, _ := s.Get("a"); v != "z" {
+		t.Errorf("Get after Flush and reload: %q, want %q", v, "z")
+	}
+}
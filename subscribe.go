@@ -10,7 +10,7 @@ import (
 
 // Broadcast sends an update to all chats in the table.
 func (bm *BotMaid) Broadcast(key string, m *Message) {
-	cs := bm.Redis.SMembers("subscribe_" + key).Val()
+	cs, _ := bm.Store.SMembers("subscribe_" + key)
 
 	for _, v := range cs {
 		args := strings.Split(v, "|")
@@ -28,28 +28,36 @@ func (bm *BotMaid) Broadcast(key string, m *Message) {
 	}
 }
 
+// SubscribeCommandDo subscribes or unsubscribes an entry on the chat for masters. The entry is read from Params if the command is added with Args SubscribeCommandArgs, or from the first argument otherwise.
 func (bm *BotMaid) SubscribeCommandDo(u *Update, f *pflag.FlagSet) bool {
-	if !bm.IsMaster(u.User) {
-		bm.Reply(u, fmt.Sprintf(bm.Words["noPermission"], bm.At(u.User), "subscribe"))
+	if !bm.requireMaster(u) {
 		return true
 	}
 
-	if len(f.Args()) == 1 || !Contains(bm.SubEntries, f.Args()[1]) {
+	entry := u.Message.StringParam("ENTRY")
+	if _, ok := u.Message.Params["ENTRY"]; !ok && len(f.Args()) == 2 {
+		entry = f.Args()[1]
+	}
+	if !Contains(bm.SubEntries, entry) {
 		bm.Reply(u, fmt.Sprintf(bm.Words["correctSubEntries"], ListToString(bm.SubEntries, bm.Words["subEntriesFormat"], bm.Words["subEntriesSeparator"], bm.Words["subEntriesAnd"])))
 		return true
 	}
 
-	if len(f.Args()) == 2 {
-		if bm.Redis.SIsMember("subscribe_"+f.Args()[1], u.Bot.ID+"|"+u.Chat.Type+"|"+strconv.FormatInt(u.Chat.ID, 10)).Val() {
-			bm.Redis.SRem("subscribe_"+f.Args()[1], u.Bot.ID+"|"+u.Chat.Type+"|"+strconv.FormatInt(u.Chat.ID, 10))
-			bm.Reply(u, fmt.Sprintf(bm.Words["unsubscribed"], f.Args()[1]))
-			return true
-		}
-
-		bm.Redis.SAdd("subscribe_"+f.Args()[1], u.Bot.ID+"|"+u.Chat.Type+"|"+strconv.FormatInt(u.Chat.ID, 10))
-		bm.Reply(u, fmt.Sprintf(bm.Words["subscribed"], f.Args()[1]))
+	chat := u.Bot.ID + "|" + u.Chat.Type + "|" + strconv.FormatInt(u.Chat.ID, 10)
+	if is, _ := bm.Store.SIsMember("subscribe_"+entry, chat); is {
+		bm.Store.SRem("subscribe_"+entry, chat)
+		bm.Reply(u, fmt.Sprintf(bm.Words["unsubscribed"], entry))
 		return true
 	}
 
-	return false
+	bm.Store.SAdd("subscribe_"+entry, chat)
+	bm.Reply(u, fmt.Sprintf(bm.Words["subscribed"], entry))
+	return true
+}
+
+// SubscribeCommandArgs returns the positional arguments of subscribe, the entry is one of SubEntries at the time of calling.
+func (bm *BotMaid) SubscribeCommandArgs() []Arg {
+	return []Arg{
+		{Name: "ENTRY", Type: ArgEnum, Enum: bm.SubEntries},
+	}
 }
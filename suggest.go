@@ -0,0 +1,92 @@
+package botmaid
+
+import (
+	"fmt"
+	"sort"
+)
+
+// distance returns the Levenshtein distance between two strings.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// suggest returns at most 3 names of enabled commands closest to a name within Command.SuggestionDistance in the config, 0 disables suggestions.
+func (bm *BotMaid) suggest(u *Update, name string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	ss := []suggestion{}
+	seen := map[string]bool{}
+	for _, c := range bm.Commands {
+		if c.Help == nil || !bm.commandEnabled(u, c) {
+			continue
+		}
+
+		for _, v := range c.Help.Names {
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+
+			if d := distance(name, v); d <= bm.Conf.SuggestionDistance {
+				ss = append(ss, suggestion{v, d})
+			}
+		}
+	}
+
+	sort.Slice(ss, func(i, j int) bool {
+		if ss[i].distance != ss[j].distance {
+			return ss[i].distance < ss[j].distance
+		}
+		return ss[i].name < ss[j].name
+	})
+
+	names := []string{}
+	for i := 0; i < len(ss) && i < 3; i++ {
+		names = append(names, ss[i].name)
+	}
+	return names
+}
+
+// replyUndefCommand replies undefCommand with suggestions of an unknown command, it replies nothing if there is no suggestion unless always is true.
+func (bm *BotMaid) replyUndefCommand(u *Update, name string, always bool) bool {
+	names := bm.suggest(u, name)
+	if len(names) == 0 {
+		if always {
+			bm.Reply(u, fmt.Sprintf(bm.Words["undefCommand"], bm.At(u.User), name))
+		}
+		return always
+	}
+
+	bm.Reply(u, fmt.Sprintf(bm.Words["undefCommand"], bm.At(u.User), name)+"\n"+fmt.Sprintf(bm.Words["didYouMean"], ListToString(names, "\"%v\"", ", ", " or ")))
+	return true
+}
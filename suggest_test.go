@@ -0,0 +1,35 @@
+package botmaid_test
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestSuggest(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.Conf.Ban.ReplyLoop = 0
+	addEcho(h)
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			return true
+		},
+		Help: &botmaid.Help{
+			Menu:  "ecco",
+			Names: []string{"ecco"},
+		},
+	})
+
+	undef := "@user, the command \"ehco\" is unknown, please retry after checking the spelling or the \"help\" command.\n"
+	expectReplies(t, "/ehco", h.Say("/ehco a"), undef+"Did you mean \"ecco\" or \"echo\"?")
+	expectReplies(t, "/something", h.Say("/something"))
+
+	u := h.NewMessage("")
+	u.Bot = h.Bot
+	h.BotMaid.SetCommandEnabled(u, botmaid.ScopeChat, "ecco", false)
+	expectReplies(t, "/ehco with ecco disabled", h.Say("/ehco a"), undef+"Did you mean \"echo\"?")
+
+	h.BotMaid.Conf.SuggestionDistance = 0
+	expectReplies(t, "/ehco without suggestions", h.Say("/ehco a"))
+}
@@ -0,0 +1,159 @@
+package botmaid
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/pflag"
+)
+
+// Scopes of enabling or disabling commands, the state in a narrower scope overrides the one in a wider scope.
+const (
+	ScopeChat   = "chat"
+	ScopeBot    = "bot"
+	ScopeGlobal = "global"
+)
+
+func commandStateKey(u *Update, scope string) string {
+	switch scope {
+	case ScopeChat:
+		return fmt.Sprintf("commandState_%v_%v", u.Bot.ID, u.Chat.ID)
+	case ScopeBot:
+		return "commandState_" + u.Bot.ID
+	}
+	return "commandState"
+}
+
+// SetCommandEnabled enables or disables the command with the menu in a scope for the chat and the bot of an update.
+func (bm *BotMaid) SetCommandEnabled(u *Update, scope, menu string, enabled bool) {
+	v := "off"
+	if enabled {
+		v = "on"
+	}
+	bm.Store.HSet(commandStateKey(u, scope), menu, v)
+}
+
+// commandState returns the effective state of the command with the menu and the scope deciding it, the scope is empty if it is enabled by default.
+func (bm *BotMaid) commandState(u *Update, menu string) (bool, string) {
+	scopes := []string{ScopeBot, ScopeGlobal}
+	if u.Chat != nil {
+		scopes = append([]string{ScopeChat}, scopes...)
+	}
+
+	for _, s := range scopes {
+		v, _ := bm.Store.HGet(commandStateKey(u, s), menu)
+		if v != "" {
+			return v == "on", s
+		}
+	}
+	return true, ""
+}
+
+// IsCommandEnabled checks if the command with the menu is enabled in the chat of an update.
+func (bm *BotMaid) IsCommandEnabled(u *Update, menu string) bool {
+	enabled, _ := bm.commandState(u, menu)
+	return enabled
+}
+
+// commandEnabled checks if a command is enabled in the chat of an update, commands without menus and AlwaysEnabled commands are always enabled.
+func (bm *BotMaid) commandEnabled(u *Update, c *Command) bool {
+	if c.Help == nil || c.Help.Menu == "" || c.AlwaysEnabled {
+		return true
+	}
+	return bm.IsCommandEnabled(u, c.Help.Menu)
+}
+
+func (bm *BotMaid) toggleCommandDo(u *Update, f *pflag.FlagSet, enabled bool) bool {
+	if !bm.requireRole(u, RoleChatAdmin) {
+		return true
+	}
+	if len(f.Args()) < 2 {
+		return false
+	}
+
+	scope, _ := f.GetString("scope")
+	if scope != ScopeChat && scope != ScopeBot && scope != ScopeGlobal {
+		bm.Reply(u, fmt.Sprintf(bm.Words["invalidScope"], bm.At(u.User), scope))
+		return true
+	}
+
+	if scope != ScopeChat && !bm.requireMaster(u) {
+		return true
+	}
+	if scope == ScopeChat && u.Chat == nil {
+		bm.Reply(u, fmt.Sprintf(bm.Words["noPermission"], bm.At(u.User), u.Message.Command))
+		return true
+	}
+
+	for _, menu := range f.Args()[1:] {
+		var cmd *Command
+		for _, c := range bm.Commands {
+			if c.Help != nil && c.Help.Menu == menu {
+				cmd = c
+				break
+			}
+		}
+		if cmd == nil {
+			bm.Reply(u, fmt.Sprintf(bm.Words["undefCommand"], bm.At(u.User), menu))
+			return true
+		}
+		if !enabled && (cmd.AlwaysEnabled || Contains(cmd.Help.Names, u.Message.Command)) {
+			bm.Reply(u, fmt.Sprintf(bm.Words["cannotDisable"], bm.At(u.User), menu))
+			return true
+		}
+
+		bm.SetCommandEnabled(u, scope, menu, enabled)
+	}
+
+	word := "commandDisabled"
+	if enabled {
+		word = "commandEnabled"
+	}
+	bm.Reply(u, fmt.Sprintf(bm.Words[word], ListToString(f.Args()[1:], "%v", ", ", " and "), scope))
+	return true
+}
+
+// EnableCommandDo enables commands in a scope, admins of a chat could enable commands in the chat, and the bot and global scopes are for masters.
+func (bm *BotMaid) EnableCommandDo(u *Update, f *pflag.FlagSet) bool {
+	return bm.toggleCommandDo(u, f, true)
+}
+
+// DisableCommandDo disables commands in a scope like EnableCommandDo, AlwaysEnabled commands and the command itself could not be disabled.
+func (bm *BotMaid) DisableCommandDo(u *Update, f *pflag.FlagSet) bool {
+	return bm.toggleCommandDo(u, f, false)
+}
+
+func (bm *BotMaid) ToggleCommandHelpSetFlag(f *pflag.FlagSet) {
+	f.StringP("scope", "s", ScopeChat, bm.Words["scopeHelp"])
+}
+
+func (bm *BotMaid) CommandsCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if len(f.Args()) != 1 {
+		return false
+	}
+
+	lines := []string{}
+	for _, c := range bm.Commands {
+		if c.Help == nil || c.Help.Menu == "" {
+			continue
+		}
+
+		enabled, scope := bm.commandState(u, c.Help.Menu)
+		state := bm.Words["commandOn"]
+		if !enabled {
+			state = bm.Words["commandOff"]
+		}
+		if scope != "" {
+			state += " (" + scope + ")"
+		}
+		lines = append(lines, fmt.Sprintf("\n  %v  %v", c.Help.Menu, state))
+	}
+	sort.Strings(lines)
+
+	s := ""
+	for _, v := range lines {
+		s += v
+	}
+	bm.Reply(u, fmt.Sprintf(bm.Words["commandList"], s))
+	return true
+}
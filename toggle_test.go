@@ -0,0 +1,51 @@
+package botmaid_test
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+	"github.com/the-cattail/botmaid/botmaidtest"
+)
+
+func addToggleCommands(h *botmaidtest.Harness) {
+	for _, v := range []struct {
+		name string
+		do   func(*botmaid.Update, *pflag.FlagSet) bool
+	}{
+		{"enable", h.BotMaid.EnableCommandDo},
+		{"disable", h.BotMaid.DisableCommandDo},
+	} {
+		h.BotMaid.AddCommand(&botmaid.Command{
+			Do:            v.do,
+			Permission:    botmaid.RoleChatAdmin,
+			AlwaysEnabled: true,
+			Help: &botmaid.Help{
+				Menu:    v.name,
+				Names:   []string{v.name},
+				SetFlag: h.BotMaid.ToggleCommandHelpSetFlag,
+			},
+		})
+	}
+}
+
+func TestToggle(t *testing.T) {
+	h := newHarness(t)
+	addEcho(h)
+	addToggleCommands(h)
+	h.API.ChatAdmins = []int64{1}
+	h.BotMaid.Conf.Ban.ReplyLoop = 0
+
+	expectReplies(t, "/disable echo", h.Say("/disable echo"), "echo has been disabled in the chat scope.")
+	expectReplies(t, "/echo when disabled", h.Say("/echo hi"))
+	expectReplies(t, "/echo in another chat", sayAs(h, 2, "/echo hi"), "hi")
+	expectReplies(t, "/disable enable", h.Say("/disable enable"), `@user, the command "enable" cannot be disabled.`)
+	expectReplies(t, "/disable disable", h.Say("/disable disable"), `@user, the command "disable" cannot be disabled.`)
+	expectReplies(t, "/disable -s global echo", h.Say("/disable -s global echo"), `@user, you don't have permission to use "disable"`)
+
+	u := h.NewMessage("")
+	u.Bot = h.Bot
+	h.BotMaid.SetCommandEnabled(u, botmaid.ScopeChat, "enable", false)
+	expectReplies(t, "/enable echo", h.Say("/enable echo"), "echo has been enabled in the chat scope.")
+	expectReplies(t, "/echo when enabled", h.Say("/echo hi"), "hi")
+}
@@ -0,0 +1,74 @@
+package botmaid
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Types of triggers.
+//
+// TriggerKeyword matches messages equal to the pattern, TriggerContains matches messages containing the pattern, TriggerRegex matches messages by the pattern as a regular expression, and TriggerMention matches messages mentioning the bot.
+const (
+	TriggerKeyword  = "keyword"
+	TriggerContains = "contains"
+	TriggerRegex    = "regex"
+	TriggerMention  = "mention"
+)
+
+// Trigger declares a kind of messages handled by a command, the plain text of messages are matched with surrounding spaces trimmed.
+//
+// Groups of a TriggerRegex pattern are saved in Captures of the message by their names, or by their indexes if they are unnamed, the whole match is saved by "0".
+// TriggerRegex patterns are compiled by AddCommand, a trigger with an invalid pattern or of a command not added by AddCommand never matches.
+type Trigger struct {
+	Type    string
+	Pattern string
+
+	regexp *regexp.Regexp
+}
+
+func (bm *BotMaid) matchTrigger(u *Update, t *Trigger) bool {
+	s := strings.TrimSpace(u.Message.PlainText())
+
+	switch t.Type {
+	case TriggerKeyword:
+		return s == t.Pattern
+	case TriggerContains:
+		return strings.Contains(s, t.Pattern)
+	case TriggerMention:
+		return bm.BeAt(u)
+	case TriggerRegex:
+		re := t.regexp
+		if re == nil {
+			return false
+		}
+
+		m := re.FindStringSubmatch(s)
+		if m == nil {
+			return false
+		}
+
+		for i, name := range re.SubexpNames() {
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			u.Message.Captures[name] = m[i]
+		}
+		return true
+	}
+
+	return false
+}
+
+// matchTriggers checks if a message matches any trigger of a command, and saves the captures of the first matched one.
+func (bm *BotMaid) matchTriggers(u *Update, c *Command) bool {
+	for i := range c.Triggers {
+		u.Message.Captures = map[string]string{}
+		if bm.matchTrigger(u, &c.Triggers[i]) {
+			return true
+		}
+	}
+
+	u.Message.Captures = nil
+	return false
+}
@@ -0,0 +1,57 @@
+package botmaid_test
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/the-cattail/botmaid"
+)
+
+func TestTriggers(t *testing.T) {
+	h := newHarness(t)
+	h.BotMaid.Conf.Ban.ReplyLoop = 0
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, u.Message.Captures["n"]+" "+u.Message.Captures["2"])
+			return true
+		},
+		Triggers: []botmaid.Trigger{
+			{Type: botmaid.TriggerRegex, Pattern: `^roll (?P<n>\d+)d(\d+)$`},
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, "broken")
+			return true
+		},
+		Triggers: []botmaid.Trigger{
+			{Type: botmaid.TriggerRegex, Pattern: `(`},
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, "pong")
+			return true
+		},
+		Triggers: []botmaid.Trigger{
+			{Type: botmaid.TriggerKeyword, Pattern: "ping"},
+			{Type: botmaid.TriggerContains, Pattern: "pinging"},
+		},
+	})
+	h.BotMaid.AddCommand(&botmaid.Command{
+		Do: func(u *botmaid.Update, f *pflag.FlagSet) bool {
+			h.BotMaid.Reply(u, "yes?")
+			return true
+		},
+		Triggers: []botmaid.Trigger{
+			{Type: botmaid.TriggerMention},
+		},
+	})
+
+	expectReplies(t, "roll 2d6", h.Say("  roll 2d6 "), "2 6")
+	expectReplies(t, "ping", h.Say("ping"), "pong")
+	expectReplies(t, "ping pong", h.Say("ping pong"))
+	expectReplies(t, "stop pinging me", h.Say("stop pinging me"), "pong")
+	expectReplies(t, "(", h.Say("("))
+	expectReplies(t, "hi @botmaid", h.Say("hi @botmaid"), "yes?")
+}
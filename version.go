@@ -8,12 +8,13 @@ import (
 
 func (bm *BotMaid) getLog() string {
 	log := ""
-	l := bm.Redis.LRange("log_"+bm.Redis.Get("version").Val(), 0, -1).Val()
+	v, _ := bm.Store.Get("version")
+	l, _ := bm.Store.LRange("log_"+v, 0, -1)
 	for i := range l {
 		log += fmt.Sprintf("\n%v. %v", i+1, l[i])
 	}
 
-	return fmt.Sprintf(bm.Words["fmtLog"], bm.Redis.Get("version").Val(), log)
+	return fmt.Sprintf(bm.Words["fmtLog"], v, log)
 }
 
 func (bm *BotMaid) VersionCommandDo(u *Update, f *pflag.FlagSet) bool {
@@ -23,7 +24,8 @@ func (bm *BotMaid) VersionCommandDo(u *Update, f *pflag.FlagSet) bool {
 		return true
 	}
 
-	bm.Reply(u, fmt.Sprintf(bm.Words["fmtVersion"], bm.Redis.Get("version").Val()))
+	v, _ := bm.Store.Get("version")
+	bm.Reply(u, fmt.Sprintf(bm.Words["fmtVersion"], v))
 	return true
 }
 
@@ -31,9 +33,9 @@ func (bm *BotMaid) VersionCommandHelpSetFlag(f *pflag.FlagSet) {
 	f.BoolP("log", "l", false, bm.Words["versionLogHelp"])
 }
 
+// VersetCommandDo sets the version, adds a change log or broadcasts the change log by flags, masters are the only users allowed.
 func (bm *BotMaid) VersetCommandDo(u *Update, f *pflag.FlagSet) bool {
-	if !bm.IsMaster(u.User) {
-		bm.Reply(u, fmt.Sprintf(bm.Words["noPermission"], bm.At(u.User), "verset"))
+	if !bm.requireMaster(u) {
 		return true
 	}
 
@@ -46,7 +48,7 @@ func (bm *BotMaid) VersetCommandDo(u *Update, f *pflag.FlagSet) bool {
 	}
 
 	flag := false
-	v := bm.Redis.Get("version").Val()
+	v, _ := bm.Store.Get("version")
 
 	ver, _ := f.GetString("ver")
 	if ver != "" {
@@ -54,14 +56,14 @@ func (bm *BotMaid) VersetCommandDo(u *Update, f *pflag.FlagSet) bool {
 	}
 
 	if len(f.Args()) == 2 {
-		bm.Redis.Set("version", f.Args()[1], 0)
+		bm.Store.Set("version", f.Args()[1], 0)
 		bm.Reply(u, fmt.Sprintf(bm.Words["versionSet"], f.Args()[1]))
 		flag = true
 	}
 
 	log, _ := f.GetString("log")
 	if log != "" {
-		bm.Redis.RPush("log_"+v, log)
+		bm.Store.RPush("log_"+v, log)
 		bm.Reply(u, fmt.Sprintf(bm.Words["logAdded"], log))
 		flag = true
 	}
@@ -74,3 +76,92 @@ func (bm *BotMaid) VersetCommandHelpSetFlag(f *pflag.FlagSet) {
 	f.String("log", "", bm.Words["versetLogHelp"])
 	f.Bool("broadcast", false, bm.Words["versetBroadcastHelp"])
 }
+
+// VersetSubcommands returns the subcommands of verset, which are "set VERSION", "log add [--ver VERSION] LOG" and "broadcast". Like verset, they are for masters only.
+func (bm *BotMaid) VersetSubcommands() []*Command {
+	return []*Command{
+		{
+			Do: bm.VersetSetCommandDo,
+			Args: []Arg{
+				{Name: "VERSION", Type: ArgString},
+			},
+			Help: &Help{
+				Menu:  "set",
+				Help:  bm.Words["versetSetHelp"],
+				Names: []string{"set"},
+			},
+		},
+		{
+			Help: &Help{
+				Menu:  "log",
+				Help:  bm.Words["versetLogHelp"],
+				Names: []string{"log"},
+			},
+			Subcommands: []*Command{
+				{
+					Do: bm.VersetLogAddCommandDo,
+					Args: []Arg{
+						{Name: "LOG", Type: ArgRest},
+					},
+					Help: &Help{
+						Menu:    "add",
+						Help:    bm.Words["versetLogHelp"],
+						Names:   []string{"add"},
+						SetFlag: bm.VersetLogAddCommandHelpSetFlag,
+					},
+				},
+			},
+		},
+		{
+			Do: bm.VersetBroadcastCommandDo,
+			Help: &Help{
+				Menu:  "broadcast",
+				Help:  bm.Words["versetBroadcastHelp"],
+				Names: []string{"broadcast"},
+			},
+		},
+	}
+}
+
+func (bm *BotMaid) VersetSetCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if !bm.requireMaster(u) {
+		return true
+	}
+
+	v := u.Message.StringParam("VERSION")
+	bm.Store.Set("version", v, 0)
+	bm.Reply(u, fmt.Sprintf(bm.Words["versionSet"], v))
+	return true
+}
+
+func (bm *BotMaid) VersetLogAddCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if !bm.requireMaster(u) {
+		return true
+	}
+
+	v, _ := bm.Store.Get("version")
+	ver, _ := f.GetString("ver")
+	if ver != "" {
+		v = ver
+	}
+
+	log := u.Message.StringParam("LOG")
+	bm.Store.RPush("log_"+v, log)
+	bm.Reply(u, fmt.Sprintf(bm.Words["logAdded"], log))
+	return true
+}
+
+func (bm *BotMaid) VersetLogAddCommandHelpSetFlag(f *pflag.FlagSet) {
+	f.String("ver", "", bm.Words["versetVerHelp"])
+}
+
+func (bm *BotMaid) VersetBroadcastCommandDo(u *Update, f *pflag.FlagSet) bool {
+	if !bm.requireMaster(u) {
+		return true
+	}
+
+	bm.Broadcast("log", &Message{
+		Content: bm.Words["upgraded"] + bm.getLog(),
+	})
+	return true
+}
@@ -0,0 +1,56 @@
+package botmaid
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+type webhookServer struct {
+	mux   *http.ServeMux
+	paths map[string]bool
+}
+
+var (
+	webhookServersMu sync.Mutex
+	webhookServers   = map[string]*webhookServer{}
+)
+
+// handleWebhook registers a handler on the path of a server listening on the address, the server is shared by all bots listening on the same address. It returns an error if the path has been registered on the address.
+func handleWebhook(listen, path string, h http.Handler, errors ErrorChannel) error {
+	webhookServersMu.Lock()
+	defer webhookServersMu.Unlock()
+
+	s, ok := webhookServers[listen]
+	if !ok {
+		s = &webhookServer{
+			mux:   http.NewServeMux(),
+			paths: map[string]bool{},
+		}
+		webhookServers[listen] = s
+
+		go func() {
+			err := http.ListenAndServe(listen, s.mux)
+			if err != nil {
+				errors <- err
+			}
+		}()
+	}
+
+	if s.paths[path] {
+		return fmt.Errorf("%v%v has been used by another bot", listen, path)
+	}
+	s.paths[path] = true
+
+	s.mux.Handle(path, h)
+	return nil
+}
+
+// claimWebhook records the address and the path listened by a bot in the config, it returns an error if another bot has claimed them.
+func (bm *BotMaid) claimWebhook(botID, listen, path string) error {
+	if id, ok := bm.webhooks[listen+path]; ok {
+		return fmt.Errorf("Init botmaid: %v and %v both listen on %v%v", id, botID, listen, path)
+	}
+	bm.webhooks[listen+path] = botID
+	return nil
+}
@@ -0,0 +1,101 @@
+package botmaid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestHandleWebhook(t *testing.T) {
+	errors := make(ErrorChannel, 1)
+	h := http.NotFoundHandler()
+
+	if err := handleWebhook("127.0.0.1:0", "/a", h, errors); err != nil {
+		t.Fatalf("handleWebhook: %v", err)
+	}
+	if err := handleWebhook("127.0.0.1:0", "/b", h, errors); err != nil {
+		t.Fatalf("handleWebhook on another path: %v", err)
+	}
+	if err := handleWebhook("127.0.0.1:0", "/a", h, errors); err == nil {
+		t.Fatal("handleWebhook on a registered path: no error")
+	}
+}
+
+func TestReverseWebsocketSharingPath(t *testing.T) {
+	pc := &PullConfig{RetryWaitingTime: time.Second}
+	a := &APICqhttp{ReverseListen: "127.0.0.1:0"}
+	b := &APICqhttp{ReverseListen: "127.0.0.1:0"}
+	a.connect(pc)
+	b.connect(pc)
+
+	var err error
+	select {
+	case err = <-a.errors:
+	case err = <-b.errors:
+	case <-time.After(time.Second):
+		t.Fatal("no error for bots sharing a reverse WebSocket path")
+	}
+	if !strings.Contains(err.Error(), "127.0.0.1:0/ws has been used by another bot") {
+		t.Errorf("error: %v", err)
+	}
+}
+
+func TestClaimWebhook(t *testing.T) {
+	bm := &BotMaid{webhooks: map[string]string{}}
+
+	if err := bm.claimWebhook("QQ_1", ":8080", "/ws"); err != nil {
+		t.Fatalf("claimWebhook: %v", err)
+	}
+	if err := bm.claimWebhook("QQ_2", ":8080", "/qq2"); err != nil {
+		t.Fatalf("claimWebhook on another path: %v", err)
+	}
+	if err := bm.claimWebhook("QQ_3", ":8080", "/ws"); err == nil || !strings.Contains(err.Error(), "QQ_1 and QQ_3") {
+		t.Fatalf("claimWebhook on a claimed path: %v", err)
+	}
+}
+
+func TestReverseWebsocketClientRole(t *testing.T) {
+	a := &APICqhttp{}
+	a.connect(&PullConfig{RetryWaitingTime: time.Second})
+
+	s := httptest.NewServer(a.reverseHandler())
+	defer s.Close()
+	endpoint := "ws" + strings.TrimPrefix(s.URL, "http")
+
+	dial := func(role string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(endpoint, http.Header{"X-Client-Role": []string{role}})
+		if err != nil {
+			t.Fatalf("Dial as %v: %v", role, err)
+		}
+		return conn
+	}
+	current := func() *websocket.Conn {
+		time.Sleep(50 * time.Millisecond)
+		a.connMu.Lock()
+		defer a.connMu.Unlock()
+		return a.conn
+	}
+
+	event := dial("Event")
+	defer event.Close()
+	if current() != nil {
+		t.Fatal("the Event connection is used for API calls")
+	}
+
+	api := dial("API")
+	defer api.Close()
+	if current() == nil {
+		t.Fatal("the API connection is not used for API calls")
+	}
+
+	event.WriteJSON(map[string]interface{}{"post_type": "meta_event"})
+	select {
+	case <-a.events:
+	case <-time.After(time.Second):
+		t.Error("no event from the Event connection")
+	}
+}